@@ -0,0 +1,76 @@
+package mock_server_client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+// callbackHandler adapts Go HTTP callback to the handler of requests which mock server app sends over callback websocket.
+func callbackHandler(callback func(*http.Request) *http.Response) client.CallbackHandler {
+	return func(rq client.HTTPRequest) client.HTTPResponse {
+		r, err := toHTTPRequest(rq)
+		if err != nil {
+			return client.HTTPResponse{StatusCode: http.StatusBadRequest, Body: err.Error()}
+		}
+		rs := callback(r)
+		if rs == nil {
+			return client.HTTPResponse{StatusCode: http.StatusNotFound}
+		}
+		return toClientResponse(rs)
+	}
+}
+
+func toHTTPRequest(rq client.HTTPRequest) (*http.Request, error) {
+	u := url.URL{
+		Path:     rq.Path,
+		RawQuery: url.Values(fromClientMultiValues(rq.QueryStringParameters)).Encode(),
+	}
+	body, _ := client.BodyBytes(rq.Body)
+	r, err := http.NewRequest(rq.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range rq.Headers {
+		for _, value := range client.Values(v) {
+			r.Header.Add(k, value)
+		}
+	}
+	r.Host = r.Header.Get("Host")
+	return r, nil
+}
+
+func toClientResponse(rs *http.Response) client.HTTPResponse {
+	out := client.HTTPResponse{
+		StatusCode: rs.StatusCode,
+		Headers:    map[string]interface{}{},
+	}
+	if out.StatusCode == 0 {
+		out.StatusCode = http.StatusOK
+	}
+	for k, v := range rs.Header {
+		out.Headers[k] = v
+	}
+
+	if rs.Body == nil {
+		return out
+	}
+	defer rs.Body.Close()
+	body, err := ioutil.ReadAll(rs.Body)
+	if err != nil || len(body) == 0 {
+		return out
+	}
+	if utf8.Valid(body) {
+		out.Body = string(body)
+	} else {
+		out.Body = map[string]interface{}{
+			"type":        "BINARY",
+			"base64Bytes": body,
+		}
+	}
+	return out
+}
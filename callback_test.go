@@ -0,0 +1,106 @@
+package mock_server_client_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func postPet(t testing.TB, url string, p pet) int {
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	rs, err := http.Post(url, "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+	require.NoError(t, rs.Body.Close())
+	return rs.StatusCode
+}
+
+func TestCallback(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	var mu sync.Mutex
+	var stored []pet
+
+	add := mock.On(http.MethodPost, "/pets").
+		RespondWith(func(r *http.Request) *http.Response {
+			p := pet{}
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				return &http.Response{StatusCode: http.StatusBadRequest}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			stored = append(stored, p)
+			return &http.Response{StatusCode: http.StatusCreated}
+		}).
+		NumCalls(2)
+	all := mock.On(http.MethodGet, "/pets").
+		RespondWith(func(r *http.Request) *http.Response {
+			mu.Lock()
+			defer mu.Unlock()
+			data, err := json.Marshal(stored)
+			if err != nil {
+				return &http.Response{StatusCode: http.StatusInternalServerError}
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(data))}
+		})
+	require.NoError(t, mock.Setup(context.Background(), add, all))
+
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "JoJo", Age: 2}))
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "PoPo", Age: 3}))
+	assert.Equal(t, []pet{{Name: "JoJo", Age: 2}, {Name: "PoPo", Age: 3}}, getPets(t, server.URL+"/pets"))
+
+	require.NoError(t, mock.Verify(context.Background(), t))
+}
+
+func TestCallbackClosedOnClear(t *testing.T) {
+	closed := make(chan struct{})
+	// control plane of mock server app which accepts any call and registers single callback websocket
+	controlPlane := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_mockserver_callback_websocket" {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		accept := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+			"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(accept[:]))
+		message := `{"type": "org.mockserver.serialization.model.WebSocketClientIdDTO", "value": "{\"clientId\": \"some-id\"}"}`
+		_, _ = rw.Write(append([]byte{0x81, byte(len(message))}, message...))
+		require.NoError(t, rw.Flush())
+		// the connection is closed by the client
+		_, _ = io.Copy(ioutil.Discard, rw)
+		close(closed)
+	}))
+	t.Cleanup(controlPlane.Close)
+
+	t.Run("test bound mock server", func(t *testing.T) {
+		mock := msc.NewForTest(t, msc.Config{BaseURL: controlPlane.URL})
+		e := mock.On(http.MethodGet, "/pets").
+			RespondWith(func(*http.Request) *http.Response { return &http.Response{StatusCode: http.StatusOK} })
+		require.NoError(t, mock.Setup(context.Background(), e))
+	})
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback websocket is not closed when the test completes")
+	}
+}
@@ -0,0 +1,275 @@
+package mock_server_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CodeGeneratorConfig configures Go code generated by GenerateExpectationsCode.
+type CodeGeneratorConfig struct {
+	// Package of the generated file, "mocks" by default.
+	Package string
+	// Function name which creates the expectations, "RecordedExpectations" by default.
+	Function string
+	// MatchHeaders are request headers which are added to request matchers, no headers are matched by default
+	// as most of them (User-Agent, Content-Length and so on) make the expectations brittle.
+	MatchHeaders []string
+}
+
+// skippedResponseHeaders are set by HTTP server itself, so they are not added to generated responses.
+var skippedResponseHeaders = map[string]struct{}{
+	"Connection":        {},
+	"Content-Length":    {},
+	"Date":              {},
+	"Keep-Alive":        {},
+	"Transfer-Encoding": {},
+}
+
+var methodConstants = map[string]string{
+	http.MethodGet:     "http.MethodGet",
+	http.MethodHead:    "http.MethodHead",
+	http.MethodPost:    "http.MethodPost",
+	http.MethodPut:     "http.MethodPut",
+	http.MethodPatch:   "http.MethodPatch",
+	http.MethodDelete:  "http.MethodDelete",
+	http.MethodConnect: "http.MethodConnect",
+	http.MethodOptions: "http.MethodOptions",
+	http.MethodTrace:   "http.MethodTrace",
+}
+
+// GenerateExpectationsCode generates Go source file with the function which creates []*Expectation from recorded
+// ones, so big stub sets can be bootstrapped by running tests against mock server app working as a proxy:
+// 		recorded, err := mock.RecordedExpectations(ctx)
+// 		...
+// 		src, err := msc.GenerateExpectationsCode(msc.CodeGeneratorConfig{Package: "stubs"}, recorded)
+// 		...
+// 		ioutil.WriteFile("stubs/recorded.go", src, 0644)
+// The generated function can be used as mock.Setup(ctx, stubs.RecordedExpectations(mock)...).
+// Repeated requests with different responses are turned into SequentialResponse, the last response is DefaultResponse.
+// Recorded path, query parameters and headers are escaped, as mock server app matches them by regexp.
+func GenerateExpectationsCode(cfg CodeGeneratorConfig, expectations []RecordedExpectation) ([]byte, error) {
+	if cfg.Package == "" {
+		cfg.Package = "mocks"
+	}
+	if cfg.Function == "" {
+		cfg.Function = "RecordedExpectations"
+	}
+
+	g := generator{cfg: cfg}
+	var body bytes.Buffer
+	for _, group := range groupRecorded(expectations, cfg.MatchHeaders) {
+		g.expectation(&body, group)
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by mock-server-client from recorded expectations. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", cfg.Package)
+	src.WriteString("import (\n")
+	if g.useJSON {
+		src.WriteString("\t\"encoding/json\"\n")
+	}
+	if g.useHTTP {
+		src.WriteString("\t\"net/http\"\n")
+	}
+	src.WriteString("\n\tmsc \"github.com/YReshetko/mock-server-client\"\n)\n\n")
+	fmt.Fprintf(&src, "// %s creates expectations recorded by mock server app.\n", cfg.Function)
+	fmt.Fprintf(&src, "func %s(m msc.MockServer) []*msc.Expectation {\n", cfg.Function)
+	src.WriteString("return []*msc.Expectation{\n")
+	src.Write(body.Bytes())
+	src.WriteString("}\n}\n")
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to format generated code")
+	}
+	return formatted, nil
+}
+
+type generator struct {
+	cfg     CodeGeneratorConfig
+	useJSON bool
+	useHTTP bool
+}
+
+func (g *generator) expectation(w *bytes.Buffer, group []RecordedExpectation) {
+	rq := group[0].Request
+	fmt.Fprintf(w, "m.On(%s, %s).\n", g.method(rq.Method), strconv.Quote(regexp.QuoteMeta(rq.Path)))
+
+	options := g.requestOptions(rq)
+	if len(options) > 0 {
+		w.WriteString("Request(\n")
+		for _, o := range options {
+			w.WriteString(o + ",\n")
+		}
+		w.WriteString(").\n")
+	}
+
+	responses := distinctResponses(group)
+	for i, rs := range responses {
+		method := "DefaultResponse"
+		if i < len(responses)-1 {
+			method = "SequentialResponse"
+		}
+		w.WriteString(method + "(\n")
+		for _, o := range g.responseOptions(rs) {
+			w.WriteString(o + ",\n")
+		}
+		w.WriteString(")")
+		if i < len(responses)-1 {
+			w.WriteString(".\n")
+		}
+	}
+	w.WriteString(",\n")
+}
+
+func (g *generator) method(method string) string {
+	if c, ok := methodConstants[method]; ok {
+		g.useHTTP = true
+		return c
+	}
+	return strconv.Quote(method)
+}
+
+func (g *generator) requestOptions(rq RecordedRequest) []string {
+	var options []string
+	for _, k := range sortedKeys(rq.QueryParameters) {
+		options = append(options, fmt.Sprintf("msc.WithQueryParameterValues(%s)", quoteAll(append([]string{k}, quoteMeta(rq.QueryParameters[k])...)...)))
+	}
+	for _, k := range g.cfg.MatchHeaders {
+		if values := rq.Headers.Values(k); len(values) > 0 {
+			options = append(options, fmt.Sprintf("msc.WithRequestHeaderValues(%s)", quoteAll(append([]string{http.CanonicalHeaderKey(k)}, quoteMeta(values)...)...)))
+		}
+	}
+	if len(rq.Body) == 0 {
+		return options
+	}
+
+	switch {
+	case isJSONContent(rq.Headers.Get("Content-Type")) && json.Valid(rq.Body):
+		g.useJSON = true
+		options = append(options, fmt.Sprintf("msc.WithJSONBody(json.RawMessage(%s), msc.MatchStrict)", quote(compactJSON(rq.Body))))
+	case utf8.Valid(rq.Body):
+		options = append(options, fmt.Sprintf("msc.WithRequestBody(%s)", quote(string(rq.Body))))
+	default:
+		options = append(options, fmt.Sprintf("msc.WithBinaryBody([]byte(%s))", strconv.Quote(string(rq.Body))))
+	}
+	return options
+}
+
+func (g *generator) responseOptions(rs RecordedResponse) []string {
+	options := []string{fmt.Sprintf("msc.WithStatusCode(%d)", rs.StatusCode)}
+	for _, k := range sortedKeys(rs.Headers) {
+		if _, ok := skippedResponseHeaders[k]; ok || k == "Set-Cookie" {
+			continue
+		}
+		// WithResponseHeader keeps single value of the header
+		options = append(options, fmt.Sprintf("msc.WithResponseHeader(%s)", quoteAll(k, rs.Headers[k][0])))
+	}
+	for _, k := range sortedKeys(rs.Cookies) {
+		options = append(options, fmt.Sprintf("msc.WithResponseCookie(%s)", quoteAll(k, rs.Cookies[k])))
+	}
+	if len(rs.Body) == 0 {
+		return options
+	}
+
+	if utf8.Valid(rs.Body) {
+		body := string(rs.Body)
+		if isJSONContent(rs.Headers.Get("Content-Type")) && json.Valid(rs.Body) {
+			body = compactJSON(rs.Body)
+		}
+		options = append(options, fmt.Sprintf("msc.WithResponseBody(%s)", quote(body)))
+	} else {
+		options = append(options, fmt.Sprintf(
+			"msc.WithResponseBody(map[string]interface{}{\"type\": \"BINARY\", \"base64Bytes\": []byte(%s)})",
+			strconv.Quote(string(rs.Body))))
+	}
+	return options
+}
+
+// groupRecorded groups recorded expectations with the same request matcher keeping the order of first occurrence.
+func groupRecorded(expectations []RecordedExpectation, matchHeaders []string) [][]RecordedExpectation {
+	var groups [][]RecordedExpectation
+	index := map[string]int{}
+	for _, e := range expectations {
+		key := requestKey(e.Request, matchHeaders)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], e)
+	}
+	return groups
+}
+
+func requestKey(rq RecordedRequest, matchHeaders []string) string {
+	headers := map[string][]string{}
+	for _, k := range matchHeaders {
+		headers[http.CanonicalHeaderKey(k)] = rq.Headers.Values(k)
+	}
+	data, _ := json.Marshal([]interface{}{rq.Method, rq.Path, rq.QueryParameters, headers, rq.Body})
+	return string(data)
+}
+
+// distinctResponses drops repeated responses in the end of the group as DefaultResponse returns them anyway.
+func distinctResponses(group []RecordedExpectation) []RecordedResponse {
+	responses := make([]RecordedResponse, len(group))
+	for i, e := range group {
+		responses[i] = e.Response
+	}
+	for len(responses) > 1 && reflect.DeepEqual(responses[len(responses)-1], responses[len(responses)-2]) {
+		responses = responses[:len(responses)-1]
+	}
+	return responses
+}
+
+func isJSONContent(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func compactJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// quote prefers raw string literal as it's easier to read JSON in generated code.
+func quote(s string) string {
+	if strings.ContainsAny(s, "`\r") || !strconv.CanBackquote(strings.ReplaceAll(s, "\n", "")) {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func quoteAll(values ...string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteMeta escapes regexp metacharacters of recorded values, as mock server app matches path, query parameters
+// and headers by regexp.
+func quoteMeta(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	return quoted
+}
@@ -0,0 +1,73 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestRecordExpectations(t *testing.T) {
+	realService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"name": "Real", "age": 7}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(realService.Close)
+
+	proxy, proxyServer, err := msc.NewInMemoryProxyMockServer(realService.URL)
+	require.NoError(t, err)
+	t.Cleanup(proxyServer.Close)
+
+	assert.Equal(t, pet{Name: "Real", Age: 7}, getPet(t, proxyServer.URL+"/pets/7"))
+	assert.Equal(t, http.StatusCreated, postPet(t, proxyServer.URL+"/pets", pet{Name: "JoJo", Age: 2}))
+
+	recorded, err := proxy.RecordedExpectations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+
+	src, err := msc.GenerateExpectationsCode(msc.CodeGeneratorConfig{Package: "stubs"}, recorded)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "package stubs")
+	assert.Contains(t, string(src), `m.On(http.MethodGet, "/pets/7")`)
+	assert.Contains(t, string(src), "msc.WithResponseBody(`{\"age\":7,\"name\":\"Real\"}`)")
+	assert.Contains(t, string(src), "msc.WithJSONBody(json.RawMessage(`{\"age\":2,\"name\":\"JoJo\"}`), msc.MatchStrict)")
+	assert.Contains(t, string(src), "msc.WithStatusCode(201)")
+}
+
+func TestGenerateExpectationsCodeEscapesMatchers(t *testing.T) {
+	recorded := []msc.RecordedExpectation{{
+		Request: msc.RecordedRequest{
+			Method:          http.MethodGet,
+			Path:            "/v1.0/pets/(7)",
+			QueryParameters: map[string][]string{"name": {"Jo*Jo", "a+b"}},
+			Headers:         http.Header{"X-Version": {"1.0"}},
+		},
+		Response: msc.RecordedResponse{StatusCode: http.StatusOK},
+	}}
+
+	src, err := msc.GenerateExpectationsCode(msc.CodeGeneratorConfig{MatchHeaders: []string{"X-Version"}}, recorded)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), `m.On(http.MethodGet, "/v1\\.0/pets/\\(7\\)")`)
+	assert.Contains(t, string(src), `msc.WithQueryParameterValues("name", "Jo\\*Jo", "a\\+b")`)
+	assert.Contains(t, string(src), `msc.WithRequestHeaderValues("X-Version", "1\\.0")`)
+
+	// the escaped matchers match the recorded request
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+	e := mock.On(http.MethodGet, "/v1\\.0/pets/\\(7\\)").
+		Request(msc.WithQueryParameterValues("name", "Jo\\*Jo", "a\\+b")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK))
+	require.NoError(t, mock.Setup(context.Background(), e))
+	assert.Equal(t, http.StatusOK, get(t, server.URL+"/v1.0/pets/(7)?name=Jo*Jo&name=a%2Bb"))
+	assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/v1.0/pets/(7)?name=JooJo&name=a%2Bb"))
+}
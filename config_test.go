@@ -0,0 +1,110 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+// writeCA writes PEM encoded certificate to the temporary file and returns its path.
+func writeCA(t *testing.T, der []byte) string {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return caFile
+}
+
+// otherCA returns self-signed CA certificate which didn't sign the certificate of httptest.Server.
+func otherCA(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return der
+}
+
+func TestTLSControlPlane(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	received := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, paths...)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+	}))
+	// handshake of the client which doesn't trust the certificate fails
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	mock := msc.NewMockServer(msc.Config{
+		BaseURL: server.URL + "/mockserver/",
+		TLS:     &msc.TLSConfig{CAFile: writeCA(t, server.Certificate().Raw)},
+		Timeout: time.Second,
+	})
+	require.NoError(t, mock.Reset(context.Background()))
+	assert.Equal(t, []string{"/mockserver/reset"}, received())
+
+	for name, tc := range map[string]struct {
+		cfg msc.Config
+		err string
+	}{
+		"system CA": {
+			cfg: msc.Config{BaseURL: server.URL, Timeout: time.Second},
+			err: "x509",
+		},
+		"wrong CA": {
+			cfg: msc.Config{BaseURL: server.URL, TLS: &msc.TLSConfig{CAFile: writeCA(t, otherCA(t))}, Timeout: time.Second},
+			err: "certificate signed by unknown authority",
+		},
+		"wrong server name": {
+			cfg: msc.Config{
+				BaseURL: server.URL,
+				TLS:     &msc.TLSConfig{CAFile: writeCA(t, server.Certificate().Raw), ServerName: "mockserver.local"},
+				Timeout: time.Second,
+			},
+			err: "mockserver.local",
+		},
+		"missing CA file": {
+			cfg: msc.Config{BaseURL: server.URL, TLS: &msc.TLSConfig{CAFile: "missing.pem"}},
+			err: "unable to read CA file",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := msc.NewMockServer(tc.cfg).Reset(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+	assert.Equal(t, []string{"/mockserver/reset"}, received(), "requests of failed handshakes are not received")
+}
@@ -0,0 +1,105 @@
+package mock_server_client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+// defaultPollInterval is used by WaitForCalls and by eventually verification if the interval isn't positive.
+const defaultPollInterval = 50 * time.Millisecond
+
+// VerifyEventually checks the Expectation the same way as VerifyExpectation does, but it polls mock server app
+// until NumCalls and all assertions are satisfied, so the requests sent by background workers of the system
+// under test can be verified without sleeps:
+// 		mock.VerifyEventually(ctx, t, e, 5*time.Second, 100*time.Millisecond)
+// Test fails with the last observed state if the Expectation isn't satisfied within the timeout or the context
+// is done. The test fails and the error is returned if mock server app can not be reached.
+func (m *mockServer) VerifyEventually(ctx context.Context, t testing.TB, expectation *Expectation, timeout, interval time.Duration) error {
+	t.Helper()
+	return report(t, m.CheckEventually(ctx, expectation, timeout, interval))
+}
+
+// CheckEventually checks the Expectation the same way as VerifyEventually does, but returns *VerificationError.
+func (m *mockServer) CheckEventually(ctx context.Context, expectation *Expectation, timeout, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *ExpectationReport
+	for {
+		r, err := m.reportExpectation(ctx, expectation)
+		switch {
+		case err != nil && (ctx.Err() == nil || last == nil):
+			return errors.Wrapf(err, "verification failed on expectation %s", expectation)
+		case err == nil && r.Passed:
+			return nil
+		case err == nil:
+			last = &r
+		}
+
+		select {
+		case <-ctx.Done():
+			return eventuallyFailed(last, expectation, timeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// eventuallyFailed returns failures of the last observed state of the Expectation.
+func eventuallyFailed(last *ExpectationReport, expectation *Expectation, timeout time.Duration, cause error) error {
+	calls := 0
+	for _, c := range last.Calls {
+		if !c.Missing {
+			calls++
+		}
+	}
+	report := VerificationReport{Expectations: []ExpectationReport{*last}}
+	verificationErr, _ := report.Error().(*VerificationError)
+	verificationErr.Failures = append([]VerificationFailure{{
+		Expectation: expectation.String(),
+		Call:        NoCall,
+		Reason:      fmt.Sprintf("not satisfied within %s (%s), last observed num calls: %d", timeout, cause, calls),
+	}}, verificationErr.Failures...)
+	return verificationErr
+}
+
+// WaitForCalls waits until mock server app receives at least n requests matching the Expectation, so the test can
+// continue when the system under test did its asynchronous work:
+// 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+// 		defer cancel()
+// 		err := mock.WaitForCalls(ctx, e, 2)
+// The error contains the last observed number of calls if the context is done first.
+func (m *mockServer) WaitForCalls(ctx context.Context, expectation *Expectation, n int) error {
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	calls := 0
+	for {
+		rs, err := m.client.Retrieve(ctx, client.RetrieveRequest(clientHttpRequest(expectation.request)))
+		if err != nil && ctx.Err() == nil {
+			return errors.Wrapf(err, "unable to get recorded requests of expectation %s", expectation)
+		}
+		if err == nil {
+			calls = len(rs)
+		}
+		if calls >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "expectation %s received %d of %d calls", expectation, calls, n)
+		case <-ticker.C:
+		}
+	}
+}
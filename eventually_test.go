@@ -0,0 +1,94 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestEventually(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byID := mock.On(http.MethodGet, "/pets/{id}").
+		Name("Pet by id").
+		Request(msc.WithPathParameter("id", "[0-9]+")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "JoJo", Age: 2})).
+		NumCalls(2).
+		AssertionOnLastCall(msc.NewAssertion().WithPath("/pets/2"))
+	require.NoError(t, mock.Setup(context.Background(), byID))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	getPet(t, server.URL+"/pets/1")
+	require.NoError(t, mock.WaitForCalls(ctx, byID, 1))
+
+	verified := make(chan error, 1)
+	go func() {
+		verified <- mock.CheckEventually(ctx, byID, 5*time.Second, 10*time.Millisecond)
+	}()
+	getPet(t, server.URL+"/pets/2")
+	assert.NoError(t, <-verified)
+	assert.NoError(t, mock.VerifyEventually(context.Background(), t, byID, time.Second, 10*time.Millisecond))
+
+	// no more calls are made, so the checks fail when the timeout is over
+	var verificationErr *msc.VerificationError
+	err := mock.CheckEventually(context.Background(), byID.NumCalls(3), 50*time.Millisecond, 10*time.Millisecond)
+	require.ErrorAs(t, err, &verificationErr)
+	assert.Contains(t, verificationErr.Failures[0].Reason, "last observed num calls: 2")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, mock.WaitForCalls(ctx, byID, 3), context.DeadlineExceeded)
+}
+
+func TestWaitForCalls(t *testing.T) {
+	// polls receives each retrieve of recorded requests after its response is decided
+	polls := make(chan struct{})
+	var mu sync.Mutex
+	recorded := "[]"
+	controlPlane := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/retrieve" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		body := recorded
+		mu.Unlock()
+		polls <- struct{}{}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(controlPlane.Close)
+
+	mock := msc.NewMockServer(msc.Config{BaseURL: controlPlane.URL})
+	byID := mock.On(http.MethodGet, "/pets/{id}")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	waited := make(chan error, 1)
+	go func() {
+		waited <- mock.WaitForCalls(ctx, byID, 1)
+	}()
+
+	<-polls
+	// the first poll finds no calls, so WaitForCalls has to poll again instead of returning
+	select {
+	case err := <-waited:
+		require.FailNow(t, "WaitForCalls returned before the call", "%v", err)
+	case <-polls:
+	}
+
+	mu.Lock()
+	recorded = `[{"method": "GET", "path": "/pets/1"}]`
+	mu.Unlock()
+	<-polls
+	require.NoError(t, <-waited)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	goErr "errors"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
@@ -18,15 +20,26 @@ type ErrorsClientSuite struct {
 
 	client *errors.Client
 	mock   msc.MockServer
+	server *httptest.Server
 }
 
+// SetupSuite runs the suite against mock server app when MOCKSERVER_URL is set (see `make examples-docker`),
+// otherwise against in-memory mock server
 func (c *ErrorsClientSuite) SetupSuite() {
-	c.client = errors.NewClient("http://localhost:1080")
-	c.mock = msc.NewMockServer(msc.Config{
-		Host:    "localhost",
-		Port:    1080,
-		Verbose: true,
-	})
+	url := os.Getenv("MOCKSERVER_URL")
+	if url == "" {
+		c.mock, c.server = msc.NewInMemoryMockServer()
+		url = c.server.URL
+	} else {
+		c.mock = msc.NewMockServer(msc.Config{BaseURL: url})
+	}
+	c.client = errors.NewClient(url)
+}
+
+func (c *ErrorsClientSuite) TearDownSuite() {
+	if c.server != nil {
+		c.server.Close()
+	}
 }
 
 func (c *ErrorsClientSuite) TestTimeout() {
@@ -3,6 +3,8 @@ package form_test
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -16,15 +18,26 @@ type FormClientSuite struct {
 
 	client *form.Client
 	mock   msc.MockServer
+	server *httptest.Server
 }
 
+// SetupSuite runs the suite against mock server app when MOCKSERVER_URL is set (see `make examples-docker`),
+// otherwise against in-memory mock server
 func (c *FormClientSuite) SetupSuite() {
-	c.client = form.NewFormClient("http://localhost:1080")
-	c.mock = msc.NewMockServer(msc.Config{
-		Host:    "localhost",
-		Port:    1080,
-		Verbose: true,
-	})
+	url := os.Getenv("MOCKSERVER_URL")
+	if url == "" {
+		c.mock, c.server = msc.NewInMemoryMockServer()
+		url = c.server.URL
+	} else {
+		c.mock = msc.NewMockServer(msc.Config{BaseURL: url})
+	}
+	c.client = form.NewFormClient(url)
+}
+
+func (c *FormClientSuite) TearDownSuite() {
+	if c.server != nil {
+		c.server.Close()
+	}
 }
 
 func (c *FormClientSuite) TestFormSubmission() {
@@ -3,6 +3,8 @@ package pet_test
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -16,15 +18,26 @@ type PetClientSuite struct {
 
 	client *pet.PetClient
 	mock   msc.MockServer
+	server *httptest.Server
 }
 
+// SetupSuite runs the suite against mock server app when MOCKSERVER_URL is set (see `make examples-docker`),
+// otherwise against in-memory mock server
 func (c *PetClientSuite) SetupSuite() {
-	c.client = pet.NewPetClient("http://localhost:1080")
-	c.mock = msc.NewMockServer(msc.Config{
-		Host:    "localhost",
-		Port:    1080,
-		Verbose: true,
-	})
+	url := os.Getenv("MOCKSERVER_URL")
+	if url == "" {
+		c.mock, c.server = msc.NewInMemoryMockServer()
+		url = c.server.URL
+	} else {
+		c.mock = msc.NewMockServer(msc.Config{BaseURL: url})
+	}
+	c.client = pet.NewPetClient(url)
+}
+
+func (c *PetClientSuite) TearDownSuite() {
+	if c.server != nil {
+		c.server.Close()
+	}
 }
 
 func (c *PetClientSuite) SetupTest() {
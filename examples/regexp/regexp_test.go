@@ -5,6 +5,8 @@ import (
 	"github.com/YReshetko/mock-server-client/examples/regexp"
 	"github.com/google/uuid"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -17,15 +19,27 @@ type RegexpClientSuite struct {
 
 	client *regexp.Client
 	mock   msc.MockServer
+	server *httptest.Server
+	url    string
 }
 
+// SetupSuite runs the suite against mock server app when MOCKSERVER_URL is set (see `make examples-docker`),
+// otherwise against in-memory mock server
 func (c *RegexpClientSuite) SetupSuite() {
-	c.client = regexp.NewClient("http://localhost:1080")
-	c.mock = msc.NewMockServer(msc.Config{
-		Host:    "localhost",
-		Port:    1080,
-		Verbose: true,
-	})
+	c.url = os.Getenv("MOCKSERVER_URL")
+	if c.url == "" {
+		c.mock, c.server = msc.NewInMemoryMockServer()
+		c.url = c.server.URL
+	} else {
+		c.mock = msc.NewMockServer(msc.Config{BaseURL: c.url})
+	}
+	c.client = regexp.NewClient(c.url)
+}
+
+func (c *RegexpClientSuite) TearDownSuite() {
+	if c.server != nil {
+		c.server.Close()
+	}
 }
 
 func (c *RegexpClientSuite) TestHeaderRegexps() {
@@ -79,6 +93,97 @@ func (c *RegexpClientSuite) TestAuth() {
 	c.Require().NoError(c.mock.Verify(context.Background(), c.T()))
 }
 
+func (c *RegexpClientSuite) TestNegatedMatchers() {
+	notAdmin := c.mock.On(http.MethodGet, "/some/endpoint").
+		Name("Not admin without debug").
+		Request(
+			msc.WithNotRequestHeader("X-User-Role", ".+_ADMIN"),
+			msc.WithoutQueryParameter("debug"),
+		).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK)).
+		NumCalls(2)
+	others := c.mock.On(http.MethodGet, "/some/endpoint").
+		Name("Everything else").
+		Request(
+			msc.WithNotRequestHeader("X-User-Role", ".+_ADMIN"),
+			msc.WithoutQueryParameter("debug"),
+		).
+		Not().
+		DefaultResponse(msc.WithStatusCode(http.StatusForbidden)).
+		NumCalls(2)
+
+	c.Require().NoError(c.mock.Setup(context.Background(), notAdmin, others))
+
+	c.Require().NoError(c.client.Do(map[string][]string{"X-User-Role": {"READ_DATA_USER"}}, "option=1"))
+	c.Require().NoError(c.client.Do(map[string][]string{"X-User-Role": {"READ_DATA_USER"}}, "debug=true"))
+	c.Require().NoError(c.client.Do(map[string][]string{"X-User-Role": {"READ_DATA_ADMIN"}}, "option=1"))
+	c.Require().NoError(c.client.Do(map[string][]string{"X-User-Role": {"GUEST"}}, ""))
+
+	c.Require().NoError(c.mock.Verify(context.Background(), c.T()))
+}
+
+func (c *RegexpClientSuite) TestMultiValueParameters() {
+	options := c.mock.On(http.MethodGet, "/some/endpoint").
+		Name("Known options only").
+		Request(
+			msc.WithQueryParameterValues("option", "dev_.+_\\d{1,2}", "admin_.+_\\d{1,2}"),
+			msc.WithQueryParameterMatchStyle(msc.MatchingKey),
+			msc.WithOptionalRequestHeader("X-User-Role", ".+_ADMIN"),
+		).
+		DefaultResponse(msc.WithStatusCode(http.StatusAccepted)).
+		NumCalls(2)
+
+	c.Require().NoError(c.mock.Setup(context.Background(), options))
+
+	c.Require().NoError(c.client.Do(nil, "option=dev_modifier_14&option=admin_change_67"))
+	c.Require().NoError(c.client.Do(map[string][]string{"X-User-Role": {"READ_DATA_ADMIN"}}, "option=dev_modifier_14"))
+	c.Require().NoError(c.client.Do(nil, "option=dev_modifier_14&option=user_change_67"))
+	c.Require().NoError(c.client.Do(map[string][]string{"X-User-Role": {"GUEST"}}, "option=dev_modifier_14"))
+
+	c.Require().NoError(c.mock.Verify(context.Background(), c.T()))
+}
+
+func (c *RegexpClientSuite) TestCookies() {
+	session := c.mock.On(http.MethodGet, "/some/endpoint").
+		Name("Session").
+		Request(
+			msc.WithRequestCookie("theme", "dark.blue"),
+			msc.WithRequestCookieRegexp("session", "[a-z0-9]{8}-[a-z0-9-]+"),
+		).
+		DefaultResponse(
+			msc.WithStatusCode(http.StatusOK),
+			msc.WithResponseCookie("visited", "true"),
+		).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().
+			AddCookie("theme", "dark.blue").
+			WithNoCookie("debug"),
+		)
+	c.Require().NoError(c.mock.Setup(context.Background(), session))
+
+	c.Require().NoError(c.client.Do(map[string][]string{"Cookie": {"theme=dark.blue; session=" + uuid.NewString()}}, ""))
+	c.Require().NoError(c.client.Do(map[string][]string{"Cookie": {"theme=darkxblue; session=" + uuid.NewString()}}, ""))
+
+	rq, err := http.NewRequest(http.MethodGet, c.url+"/some/endpoint", nil)
+	c.Require().NoError(err)
+	rq.AddCookie(&http.Cookie{Name: "theme", Value: "dark.blue"})
+	rq.AddCookie(&http.Cookie{Name: "session", Value: "anonymous"})
+	rs, err := http.DefaultClient.Do(rq)
+	c.Require().NoError(err)
+	c.Require().NoError(rs.Body.Close())
+	c.Equal(http.StatusNotFound, rs.StatusCode)
+
+	c.Require().NoError(c.mock.Verify(context.Background(), c.T()))
+
+	rq.Header.Set("Cookie", "theme=dark.blue; session="+uuid.NewString())
+	rs, err = http.DefaultClient.Do(rq)
+	c.Require().NoError(err)
+	c.Require().NoError(rs.Body.Close())
+	c.Require().Len(rs.Cookies(), 1)
+	c.Equal("visited", rs.Cookies()[0].Name)
+	c.Equal("true", rs.Cookies()[0].Value)
+}
+
 func (c *RegexpClientSuite) SetupTest() {
 	c.Require().NoError(c.mock.Reset(context.Background()))
 }
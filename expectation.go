@@ -1,11 +1,15 @@
 package mock_server_client
 
 import (
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 
 	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/mustache"
 )
 
 // Expectation contains references to expected HTTP request and responses, required assertions and so on.
@@ -16,8 +20,16 @@ type Expectation struct {
 	name                string
 	request             *request
 	defaultResponse     *response
+	forward             *forward
+	callback            func(*http.Request) *http.Response
+	callbackClientID    string
+	clientIDs           []string
+	sequence            int
 	sequentialResponses []response
 	assertions          map[int]*assertion
+	everyCallAssertions []*assertion
+	anyCallAssertions   []*assertion
+	lastCallAssertions  []*assertion
 	numCalls            int
 
 	isBuilt bool
@@ -27,9 +39,20 @@ type request struct {
 	method      string
 	path        string
 	pathParams  map[string]string
-	queryParams map[string]string
-	headers     map[string]string
+	queryParams map[string][]string
+	headers     map[string][]string
+	cookies     map[string]string
 	body        interface{}
+	notPath     bool
+	notBody     bool
+	not         bool
+	// scoped is set for expectations of ScopedMockServer, the scope part of the matcher must not be negated
+	scoped bool
+	// scopePrefix is added to the path on mock server app
+	scopePrefix string
+
+	queryMatchStyle  client.KeyMatchStyle
+	headerMatchStyle client.KeyMatchStyle
 }
 
 type response struct {
@@ -37,9 +60,23 @@ type response struct {
 	statusCode   int
 	reasonPhrase string
 	headers      map[string]string
+	cookies      map[string]string
 	delay        *time.Duration
 	drop         bool
 	errorBytes   []byte
+	template     string
+	templateType client.TemplateType
+}
+
+type forward struct {
+	host     string
+	port     int
+	scheme   string
+	path     string
+	headers  map[string]string
+	body     interface{}
+	delay    *time.Duration
+	override bool
 }
 
 func newExpectation(method, path string) Expectation {
@@ -76,6 +113,42 @@ func (e *Expectation) DefaultResponse(opts ...ResponseOption) *Expectation {
 		opt(r)
 	}
 	e.defaultResponse = r
+	e.forward = nil
+	e.callback = nil
+	return e
+}
+
+// Forward prepares forwarding of the request to another host instead of DefaultResponse. The request is sent
+// as it is unless any of path, headers or body are overridden, in this case the rest of the request is kept.
+// Can be combined with SequentialResponse, so some calls are stubbed and the rest are passed through.
+// Can not be called after the Expectation was MockServer.Setup to mock server app, it leads the panic().
+func (e *Expectation) Forward(opts ...ForwardOption) *Expectation {
+	if e.isBuilt {
+		panic("unable to update assertion when it's already on mock server")
+	}
+	f := &forward{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	e.forward = f
+	e.defaultResponse = nil
+	e.callback = nil
+	return e
+}
+
+// RespondWith sets Go callback which computes responses instead of DefaultResponse. mock server app sends matched
+// requests to the client over the callback websocket, which is opened on MockServer.Setup and closed on
+// MockServer.Clear or MockServer.Reset.
+// The callback is called concurrently, so it has to be safe for concurrent use. Returned nil leads to 404 response.
+// Can be combined with SequentialResponse, and requests can still be checked by MockServer.Verify.
+// Can not be called after the Expectation was MockServer.Setup to mock server app, it leads the panic().
+func (e *Expectation) RespondWith(callback func(*http.Request) *http.Response) *Expectation {
+	if e.isBuilt {
+		panic("unable to update assertion when it's already on mock server")
+	}
+	e.callback = callback
+	e.defaultResponse = nil
+	e.forward = nil
 	return e
 }
 
@@ -111,6 +184,61 @@ func (e *Expectation) AssertionAtCall(callNumber int, a *assertion) *Expectation
 	return e
 }
 
+// AssertionOnEveryCall registers the assertion which has to be satisfied by each call of the Expectation,
+// so the test doesn't depend on the order of calls made by concurrent clients.
+func (e *Expectation) AssertionOnEveryCall(a *assertion) *Expectation {
+	e.everyCallAssertions = append(e.everyCallAssertions, a)
+	return e
+}
+
+// AssertionOnAnyCall registers the assertion which has to be satisfied by at least one call of the Expectation.
+func (e *Expectation) AssertionOnAnyCall(a *assertion) *Expectation {
+	e.anyCallAssertions = append(e.anyCallAssertions, a)
+	return e
+}
+
+// AssertionOnLastCall registers the assertion which has to be satisfied by the last call of the Expectation.
+func (e *Expectation) AssertionOnLastCall(a *assertion) *Expectation {
+	e.lastCallAssertions = append(e.lastCallAssertions, a)
+	return e
+}
+
+// callAssertions returns assertions of the call with the prefixes of their names in reports.
+func (e *Expectation) callAssertions(call, calls int) []namedAssertion {
+	var out []namedAssertion
+	if a, ok := e.assertions[call]; ok {
+		out = append(out, namedAssertion{assertion: a})
+	}
+	for _, a := range e.everyCallAssertions {
+		out = append(out, namedAssertion{prefix: "every call: ", assertion: a})
+	}
+	if call == calls-1 {
+		for _, a := range e.lastCallAssertions {
+			out = append(out, namedAssertion{prefix: "last call: ", assertion: a})
+		}
+	}
+	return out
+}
+
+type namedAssertion struct {
+	prefix    string
+	assertion *assertion
+}
+
+// Not negates the whole request matcher, so the Expectation matches all requests except ones described by
+// On and Request, for example every user except the admin:
+// 		mock.On(http.MethodGet, "/users").Request(msc.WithRequestHeader("X-User", "admin")).Not()
+// The Expectation of ScopedMockServer can not be negated, mock server app would negate the scope as well and
+// match the traffic of other scopes, so MockServer.Setup returns error.
+// Can not be called after the Expectation was MockServer.Setup to mock server app, it leads the panic().
+func (e *Expectation) Not() *Expectation {
+	if e.isBuilt {
+		panic("unable to update assertion when it's already on mock server")
+	}
+	e.request.not = true
+	return e
+}
+
 // Name set Expectation name for better debug, if the name is not set the new UUID will be generated instead.
 // For example:
 // 		Test failure on named Expectation:
@@ -137,6 +265,37 @@ func (e *Expectation) String() string {
 	return e.id
 }
 
+// validate checks the parts of Expectation which can be checked locally before it's sent to mock server app.
+func (e *Expectation) validate() error {
+	if e.forward != nil && e.forward.host == "" {
+		return errors.New("forward host is required, set it by WithForwardHost")
+	}
+	if e.request.not && e.request.scoped {
+		return errors.New("scoped expectation can not be negated by Not, it would match requests of other scopes")
+	}
+	if e.request.notPath && e.request.scopePrefix != "" {
+		return errors.New("path of scoped expectation can not be negated by NotPath, it would match requests of " +
+			"other scopes, identify the scope by WithScopeHeader instead")
+	}
+	for _, r := range e.sequentialResponses {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+	if e.defaultResponse != nil {
+		return e.defaultResponse.validate()
+	}
+	return nil
+}
+
+func (r *response) validate() error {
+	if r.templateType != client.MUSTACHE {
+		return nil
+	}
+	_, err := mustache.Parse(r.template)
+	return errors.Wrap(err, "invalid mustache template")
+}
+
 func (e *Expectation) build() []client.Expectation {
 	if e.isBuilt {
 		panic("unable to build assertion more then once")
@@ -146,7 +305,8 @@ func (e *Expectation) build() []client.Expectation {
 	httpRequest := clientHttpRequest(e.request)
 
 	for i, response := range e.sequentialResponses {
-		exp := newClientExpectation(&response)
+		exp := newClientExpectation()
+		setResponse(&exp, &response)
 		exp.Times = &client.Times{
 			RemainingTimes: 1,
 			Unlimited:      false,
@@ -154,42 +314,99 @@ func (e *Expectation) build() []client.Expectation {
 		exp.Priority = len(expectations) - i
 		exp.HTTPRequest = &httpRequest
 		expectations[i] = exp
+		e.clientIDs = append(e.clientIDs, exp.ID)
 	}
 
-	defaultExp := newClientExpectation(e.defaultResponse)
+	defaultExp := newClientExpectation()
+	switch {
+	case e.forward != nil:
+		setForward(&defaultExp, e.forward)
+	case e.callback != nil:
+		defaultExp.HTTPResponseObjectCallback = &client.HTTPObjectCallback{
+			ClientID: e.callbackClientID,
+		}
+	case e.defaultResponse != nil:
+		setResponse(&defaultExp, e.defaultResponse)
+	default:
+		setResponse(&defaultExp, &response{})
+	}
 	defaultExp.Times = &client.Times{
 		Unlimited: true,
 	}
 	defaultExp.HTTPRequest = &httpRequest
 	expectations[len(expectations)-1] = defaultExp
+	e.clientIDs = append(e.clientIDs, defaultExp.ID)
 	return expectations
 }
 
-func newClientExpectation(res *response) client.Expectation {
-	e := client.Expectation{
+func newClientExpectation() client.Expectation {
+	return client.Expectation{
 		ID: uuid.NewString(),
 		TimeToLive: &client.TimeToLive{
 			Unlimited: true,
 		},
 	}
+}
 
-	if res.drop {
+func setResponse(e *client.Expectation, res *response) {
+	switch {
+	case res.drop:
 		e.HTTPError = &client.HTTPError{
 			Delay:          delay(res.delay),
 			DropConnection: true,
 			ResponseBytes:  string(res.errorBytes),
 		}
-	} else {
+	case res.template != "":
+		e.HTTPResponseTemplate = &client.HTTPResponseTemplate{
+			Template:     res.template,
+			TemplateType: res.templateType,
+			Delay:        delay(res.delay),
+		}
+	default:
 		e.HTTPResponse = &client.HTTPResponse{
 			Body:         res.body,
 			StatusCode:   res.statusCode,
 			ReasonPhrase: res.reasonPhrase,
 			Headers:      toClientHeaders(res.headers),
+			Cookies:      res.cookies,
 			Delay:        delay(res.delay),
 		}
 	}
+}
 
-	return e
+func setForward(e *client.Expectation, f *forward) {
+	socketAddress := &client.SocketAddress{
+		Host:   f.host,
+		Port:   f.port,
+		Scheme: client.Scheme(strings.ToUpper(f.scheme)),
+	}
+	if !f.override {
+		e.HTTPForward = &client.HTTPForward{
+			Host:   socketAddress.Host,
+			Port:   socketAddress.Port,
+			Scheme: socketAddress.Scheme,
+			Delay:  delay(f.delay),
+		}
+		return
+	}
+
+	headers := toClientHeaders(f.headers)
+	if f.host != "" {
+		if _, ok := f.headers["Host"]; !ok {
+			headers["Host"] = []string{f.host}
+		}
+	} else {
+		socketAddress = nil
+	}
+	e.HTTPOverrideForwardedRequest = &client.HTTPOverrideForwardedRequest{
+		HTTPRequest: &client.HTTPRequest{
+			Path:          f.path,
+			Headers:       headers,
+			Body:          f.body,
+			SocketAddress: socketAddress,
+		},
+		Delay: delay(f.delay),
+	}
 }
 
 func delay(t *time.Duration) *client.Delay {
@@ -203,13 +420,37 @@ func delay(t *time.Duration) *client.Delay {
 }
 
 func clientHttpRequest(req *request) client.HTTPRequest {
-	return client.HTTPRequest{
+	rq := client.HTTPRequest{
 		Method:                req.method,
-		Path:                  req.path,
+		Path:                  req.scopePrefix + req.path,
 		PathParameters:        toClientMap(req.pathParams),
-		QueryStringParameters: toClientMap(req.queryParams),
-		Headers:               toClientHeaders(req.headers),
+		QueryStringParameters: toClientMultiValues(req.queryParams, req.queryMatchStyle),
+		Headers:               toClientMultiValues(req.headers, req.headerMatchStyle),
+		Cookies:               req.cookies,
 		Body:                  req.body,
+		Not:                   req.not,
+	}
+	if req.notPath {
+		rq.Path = notPrefix + rq.Path
+	}
+	if req.notBody {
+		rq.Body = notBody(req.body)
+	}
+	return rq
+}
+
+// notBody wraps body into typed body matcher which is negated on mock server app.
+func notBody(body interface{}) interface{} {
+	switch b := body.(type) {
+	case nil:
+		return nil
+	case client.Body:
+		b.Not = true
+		return b
+	case string:
+		return client.Body{Type: client.StringBody, String: b, Not: true}
+	default:
+		return client.Body{Type: client.JSONBody, JSON: b, Not: true}
 	}
 }
 
@@ -221,6 +462,25 @@ func toClientMap(m map[string]string) map[string][]string {
 	return out
 }
 
+func toClientMultiValues(m map[string][]string, style client.KeyMatchStyle) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, v := range m {
+		out[k] = v
+	}
+	if style != "" {
+		out[client.KeyMatchStyleKey] = string(style)
+	}
+	return out
+}
+
+func fromClientMultiValues(m map[string]interface{}) map[string][]string {
+	out := map[string][]string{}
+	for k, v := range m {
+		out[k] = client.Values(v)
+	}
+	return out
+}
+
 func toClientHeaders(m map[string]string) map[string]interface{} {
 	out := map[string]interface{}{}
 	for k, v := range m {
@@ -0,0 +1,213 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func getPet(t testing.TB, url string) pet {
+	rs, err := http.Get(url)
+	require.NoError(t, err)
+	defer rs.Body.Close()
+	require.Equal(t, http.StatusOK, rs.StatusCode)
+	var p pet
+	require.NoError(t, json.NewDecoder(rs.Body).Decode(&p))
+	return p
+}
+
+func TestForward(t *testing.T) {
+	realService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/real/pets" || r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"Real","age":7}`))
+	}))
+	t.Cleanup(realService.Close)
+	host, port, err := net.SplitHostPort(realService.Listener.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	stubbed := mock.On(http.MethodGet, "/pets/1").
+		Name("Stubbed pet").
+		DefaultResponse(
+			msc.WithStatusCode(http.StatusOK),
+			msc.WithResponseBody(pet{Name: "Stub", Age: 1}),
+		)
+	forwarded := mock.On(http.MethodGet, "/pets/{pet_id}").
+		Name("Forwarded pets").
+		Request(
+			msc.WithPathParameter("pet_id", "[2-9]"),
+		).
+		Forward(
+			msc.WithForwardHost(host),
+			msc.WithForwardPort(p),
+			msc.WithForwardPath("/real/pets"),
+			msc.WithForwardHeader("X-Token", "secret"),
+		).
+		NumCalls(1)
+	require.NoError(t, mock.Setup(context.Background(), stubbed, forwarded))
+
+	assert.Equal(t, pet{Name: "Stub", Age: 1}, getPet(t, server.URL+"/pets/1"))
+	assert.Equal(t, pet{Name: "Real", Age: 7}, getPet(t, server.URL+"/pets/2"))
+
+	require.NoError(t, mock.VerifyExpectation(context.Background(), t, forwarded))
+}
+
+func TestForwardRequiresHost(t *testing.T) {
+	for name, opts := range map[string][]msc.ForwardOption{
+		"forward":          nil,
+		"override forward": {msc.WithForwardPath("/real/pets"), msc.WithForwardPort(8080)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock, server := msc.NewInMemoryMockServer()
+			t.Cleanup(server.Close)
+
+			err := mock.Setup(context.Background(), mock.On(http.MethodGet, "/pets").Forward(opts...))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "forward host is required")
+		})
+	}
+}
+
+func TestRequestHeaderOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts     []msc.RequestOption
+		matching [][]string
+		missing  [][]string
+	}{
+		"repeated header overwrites value": {
+			opts:     []msc.RequestOption{msc.WithRequestHeader("X-Id", "a"), msc.WithRequestHeader("X-Id", "b")},
+			matching: [][]string{{"b"}, {"a", "b"}},
+			missing:  [][]string{{"a"}},
+		},
+		"header values are added": {
+			opts:     []msc.RequestOption{msc.WithRequestHeaderValues("X-Id", "a"), msc.WithRequestHeaderValues("X-Id", "b")},
+			matching: [][]string{{"a", "b"}},
+			missing:  [][]string{{"a"}, {"b"}},
+		},
+		"repeated negated header overwrites value": {
+			opts:     []msc.RequestOption{msc.WithNotRequestHeader("X-Id", "a"), msc.WithNotRequestHeader("X-Id", "b")},
+			matching: [][]string{{"a"}},
+			missing:  [][]string{{"b"}, nil},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock, server := msc.NewInMemoryMockServer()
+			t.Cleanup(server.Close)
+
+			e := mock.On(http.MethodGet, "/pets").
+				Request(tc.opts...).
+				DefaultResponse(msc.WithStatusCode(http.StatusOK))
+			require.NoError(t, mock.Setup(context.Background(), e))
+
+			send := func(values []string) int {
+				rq, err := http.NewRequest(http.MethodGet, server.URL+"/pets", nil)
+				require.NoError(t, err)
+				rq.Header["X-Id"] = values
+				rs, err := http.DefaultClient.Do(rq)
+				require.NoError(t, err)
+				defer rs.Body.Close()
+				return rs.StatusCode
+			}
+			for _, values := range tc.matching {
+				assert.Equal(t, http.StatusOK, send(values), values)
+			}
+			for _, values := range tc.missing {
+				assert.Equal(t, http.StatusNotFound, send(values), values)
+			}
+		})
+	}
+}
+
+func TestQueryParameterOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts     []msc.RequestOption
+		matching []string
+		missing  []string
+	}{
+		"repeated parameter overwrites value": {
+			opts:     []msc.RequestOption{msc.WithQueryParameter("a", "1"), msc.WithQueryParameter("a", "2")},
+			matching: []string{"a=2", "a=1&a=2"},
+			missing:  []string{"a=1", ""},
+		},
+		"parameter values are added": {
+			opts:     []msc.RequestOption{msc.WithQueryParameterValues("a", "1"), msc.WithQueryParameterValues("a", "2")},
+			matching: []string{"a=1&a=2"},
+			missing:  []string{"a=1", "a=2"},
+		},
+		"repeated negated parameter overwrites value": {
+			opts:     []msc.RequestOption{msc.WithNotQueryParameter("a", "1"), msc.WithNotQueryParameter("a", "2")},
+			matching: []string{"a=1"},
+			missing:  []string{"a=2", ""},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock, server := msc.NewInMemoryMockServer()
+			t.Cleanup(server.Close)
+
+			e := mock.On(http.MethodGet, "/pets").
+				Request(tc.opts...).
+				DefaultResponse(msc.WithStatusCode(http.StatusOK))
+			require.NoError(t, mock.Setup(context.Background(), e))
+
+			for _, query := range tc.matching {
+				assert.Equal(t, http.StatusOK, get(t, server.URL+"/pets?"+query), query)
+			}
+			for _, query := range tc.missing {
+				assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/pets?"+query), query)
+			}
+		})
+	}
+}
+
+func TestCallAssertions(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byID := mock.On(http.MethodGet, "/pets/{id}").
+		Name("Pet by id").
+		Request(msc.WithPathParameter("id", "[0-9]+")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "JoJo", Age: 2})).
+		AssertionOnEveryCall(msc.NewAssertion().WithNoBody()).
+		AssertionOnAnyCall(msc.NewAssertion().WithPath("/pets/2")).
+		AssertionOnLastCall(msc.NewAssertion().WithPath("/pets/3"))
+	require.NoError(t, mock.Setup(context.Background(), byID))
+
+	var wg sync.WaitGroup
+	for _, id := range []int{1, 2} {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			rs, err := http.Get(server.URL + "/pets/" + strconv.Itoa(id))
+			if assert.NoError(t, err) {
+				assert.NoError(t, rs.Body.Close())
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	var verificationErr *msc.VerificationError
+	require.ErrorAs(t, mock.Check(context.Background()), &verificationErr)
+	require.Len(t, verificationErr.Failures, 1)
+	assert.Equal(t, 1, verificationErr.Failures[0].Call)
+	assert.Contains(t, verificationErr.Failures[0].Reason, "expected path /pets/3")
+
+	getPet(t, server.URL+"/pets/3")
+	assert.NoError(t, mock.Verify(context.Background(), t))
+}
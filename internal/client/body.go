@@ -0,0 +1,58 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// BodyBytes converts body in mock server app representation to bytes and content type the body has to be sent with.
+func BodyBytes(body interface{}) ([]byte, string) {
+	switch b := body.(type) {
+	case nil:
+		return nil, ""
+	case string:
+		return []byte(b), ""
+	case map[string]interface{}:
+		contentType, _ := b["contentType"].(string)
+		switch b["type"] {
+		case "STRING":
+			s, _ := b["string"].(string)
+			return []byte(s), contentType
+		case "BINARY":
+			s, _ := b["base64Bytes"].(string)
+			data, _ := base64.StdEncoding.DecodeString(s)
+			return data, contentType
+		case "JSON":
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			if s, ok := b["json"].(string); ok {
+				return []byte(s), contentType
+			}
+			data, _ := json.Marshal(b["json"])
+			return data, contentType
+		}
+	}
+	data, _ := json.Marshal(body)
+	return data, "application/json"
+}
+
+// Values converts header or parameter value in mock server app representation to the list of strings.
+func Values(v interface{}) []string {
+	switch values := v.(type) {
+	case string:
+		return []string{values}
+	case []string:
+		return values
+	case []interface{}:
+		out := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// CallbackHandler computes response for the request matched by object callback expectation.
+type CallbackHandler func(HTTPRequest) HTTPResponse
+
+// Handle calls the handler, its panic is logged and turned into 500 response, so the panic in test code fails
+// the request instead of crashing the test binary.
+func (h CallbackHandler) Handle(rq HTTPRequest) (rs HTTPResponse) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("mock server callback panic on %s %s: %v\n%s", rq.Method, rq.Path, r, debug.Stack())
+			rs = HTTPResponse{StatusCode: http.StatusInternalServerError, Body: fmt.Sprintf("callback panic: %v", r)}
+		}
+	}()
+	return h(rq)
+}
+
+const (
+	callbackURI = "/_mockserver_callback_websocket"
+
+	registrationIDHeader = "X-CLIENT-REGISTRATION-ID"
+	correlationIDHeader  = "WebSocketCorrelationId"
+
+	clientIDMessageType = "org.mockserver.serialization.model.WebSocketClientIdDTO"
+	requestMessageType  = "org.mockserver.model.HttpRequest"
+	responseMessageType = "org.mockserver.model.HttpResponse"
+	errorMessageType    = "org.mockserver.serialization.model.WebSocketErrorDTO"
+)
+
+type callbackMessage struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type callbackClientID struct {
+	ClientID string `json:"clientId"`
+}
+
+// Callback opens callback websocket to mock server app and returns client ID which has to be used
+// in HTTPObjectCallback. The handler is called for each request matched by such expectations
+// until the client is Reset or the callback is closed by CloseCallback.
+func (c *client) Callback(ctx context.Context, handler CallbackHandler) (string, error) {
+	if c.err != nil {
+		return "", c.Err()
+	}
+	id := uuid.NewString()
+	ws, err := dialWebsocket(ctx, c.basePath+callbackURI, http.Header{registrationIDHeader: {id}}, httpTransport(c.client))
+	if err != nil {
+		return "", errors.Wrap(err, "unable to open callback websocket")
+	}
+
+	clientID, err := register(ws)
+	if err != nil {
+		ws.close()
+		return "", errors.Wrap(err, "unable to register callback")
+	}
+	ws.resetDeadline()
+
+	c.mu.Lock()
+	if c.callbacks == nil {
+		c.callbacks = map[string]*websocket{}
+	}
+	c.callbacks[clientID] = ws
+	c.mu.Unlock()
+
+	go serveCallback(ws, handler)
+	return clientID, nil
+}
+
+func register(ws *websocket) (string, error) {
+	data, err := ws.read()
+	if err != nil {
+		return "", err
+	}
+	message := callbackMessage{}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return "", errors.Wrap(err, "unable to unmarshal registration message")
+	}
+	if message.Type != clientIDMessageType {
+		return "", errors.Errorf("unexpected registration message %s: %s", message.Type, message.Value)
+	}
+	id := callbackClientID{}
+	if err := json.Unmarshal([]byte(message.Value), &id); err != nil {
+		return "", errors.Wrap(err, "unable to unmarshal client id")
+	}
+	return id.ClientID, nil
+}
+
+func serveCallback(ws *websocket, handler CallbackHandler) {
+	defer ws.close()
+	for {
+		data, err := ws.read()
+		if err != nil {
+			return
+		}
+		message := callbackMessage{}
+		if err := json.Unmarshal(data, &message); err != nil || message.Type != requestMessageType {
+			continue
+		}
+		rq := HTTPRequest{}
+		if err := json.Unmarshal([]byte(message.Value), &rq); err != nil {
+			continue
+		}
+		go respond(ws, handler, rq)
+	}
+}
+
+func respond(ws *websocket, handler CallbackHandler, rq HTTPRequest) {
+	rs := handler.Handle(rq)
+	if rs.Headers == nil {
+		rs.Headers = map[string]interface{}{}
+	}
+	for k, v := range rq.Headers {
+		if strings.EqualFold(k, correlationIDHeader) {
+			rs.Headers[correlationIDHeader] = v
+		}
+	}
+
+	value, err := json.Marshal(rs)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(callbackMessage{Type: responseMessageType, Value: string(value)})
+	if err != nil {
+		return
+	}
+	_ = ws.write(data)
+}
+
+// CloseCallback closes callback websocket of the client ID returned by Callback.
+func (c *client) CloseCallback(clientID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ws, ok := c.callbacks[clientID]; ok {
+		_ = ws.close()
+		delete(c.callbacks, clientID)
+	}
+}
+
+func (c *client) closeCallbacks() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ws := range c.callbacks {
+		_ = ws.close()
+	}
+	c.callbacks = nil
+}
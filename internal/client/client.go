@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 )
@@ -16,29 +17,67 @@ var _ Client = (*client)(nil)
 
 type Client interface {
 	Expectation(context.Context, Expectation) error
+	OpenAPIExpectation(context.Context, OpenAPIExpectation) ([]Expectation, error)
 	Verify(context.Context, Verify) error
 	VerifySequence(context.Context, VerifySequence) error
 
 	Clear(context.Context, ClearRequest) error
 	Reset(context.Context) error
 	Retrieve(context.Context, RetrieveRequest) (RetrieveResponse, error)
+	RetrieveRequestResponses(context.Context, RetrieveRequest) ([]HTTPRequestAndResponse, error)
+	RetrieveActiveExpectations(context.Context, RetrieveRequest) ([]Expectation, error)
+	RetrieveRecordedExpectations(context.Context, RetrieveRequest) ([]Expectation, error)
+	RetrieveLogMessages(context.Context, RetrieveRequest) ([]string, error)
+
+	Callback(context.Context, CallbackHandler) (string, error)
+	CloseCallback(clientID string)
 }
 
 type client struct {
 	client       *http.Client
 	basePath     string
 	verboseError bool
+	// err is the config error, it's returned by each call
+	err error
+
+	mu sync.Mutex
+	// callbacks are websockets by client ID
+	callbacks map[string]*websocket
 }
 
-func NewClient(host string, port int, verbose bool) *client {
-	return &client{
-		client:       http.DefaultClient,
-		basePath:     fmt.Sprintf("http://%s:%d", host, port),
-		verboseError: verbose,
+// NewClient creates client of mock server app, invalid config is reported by Err and each call.
+func NewClient(cfg Config) *client {
+	c := &client{verboseError: cfg.Verbose}
+	c.basePath, c.err = cfg.basePath()
+	if c.err == nil {
+		c.client, c.err = cfg.httpClient()
 	}
+	return c
+}
+
+// Err returns the config error.
+func (c *client) Err() error {
+	return errors.Wrap(c.err, "invalid mock server client config")
 }
 
 func (c *client) do(ctx context.Context, uri string, rq, rs interface{}) error {
+	body, err := c.doRaw(ctx, uri, rq)
+	if err != nil {
+		return err
+	}
+
+	if rs == nil {
+		return nil
+	}
+
+	return errors.Wrap(json.Unmarshal(body, rs), "unable to unmarshal response")
+}
+
+// doRaw sends the request to mock server app and returns raw response body.
+func (c *client) doRaw(ctx context.Context, uri string, rq interface{}) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.Err()
+	}
 	if !strings.HasPrefix(uri, "/") {
 		uri = "/" + uri
 	}
@@ -47,19 +86,19 @@ func (c *client) do(ctx context.Context, uri string, rq, rs interface{}) error {
 	if rq != nil {
 		data, err := json.Marshal(rq)
 		if err != nil {
-			return errors.Wrap(err, "unable to marshal request")
+			return nil, errors.Wrap(err, "unable to marshal request")
 		}
 		reader = bytes.NewReader(data)
 	}
 
 	request, err := http.NewRequestWithContext(ctx, http.MethodPut, c.basePath+uri, reader)
 	if err != nil {
-		return errors.Wrap(err, "unable to prepare http request")
+		return nil, errors.Wrap(err, "unable to prepare http request")
 	}
 
 	response, err := c.client.Do(request)
 	if err != nil {
-		return errors.Wrap(err, "unable to call mockserver")
+		return nil, errors.Wrap(err, "unable to call mockserver")
 	}
 
 	var body []byte
@@ -67,10 +106,15 @@ func (c *client) do(ctx context.Context, uri string, rq, rs interface{}) error {
 		defer response.Body.Close()
 		body, err = ioutil.ReadAll(response.Body)
 		if err != nil {
-			return errors.Wrap(err, "unable to read response body")
+			return nil, errors.Wrap(err, "unable to read response body")
 		}
 	}
 
+	if response.StatusCode == http.StatusNotAcceptable {
+		// mock server app responds 406 with the mismatch description when verification fails
+		return nil, VerificationError(strings.TrimSpace(string(body)))
+	}
+
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		var msg string
 		if c.verboseError {
@@ -78,14 +122,10 @@ func (c *client) do(ctx context.Context, uri string, rq, rs interface{}) error {
 		} else {
 			msg = fmt.Sprintf("unexpected http status %d instead of 2xx", response.StatusCode)
 		}
-		return errors.New(msg)
+		return nil, errors.New(msg)
 	}
 
-	if rs == nil {
-		return nil
-	}
-
-	return errors.Wrap(json.Unmarshal(body, rs), "unable to unmarshal response")
+	return body, nil
 }
 
 const expectationURI = "/expectation"
@@ -97,6 +137,17 @@ func (c *client) Expectation(ctx context.Context, request Expectation) error {
 	)
 }
 
+const openAPIURI = "/openapi"
+
+func (c *client) OpenAPIExpectation(ctx context.Context, request OpenAPIExpectation) ([]Expectation, error) {
+	var rs []Expectation
+	err := errors.Wrap(
+		c.do(ctx, openAPIURI, request, &rs),
+		"unable to setup openapi expectations",
+	)
+	return rs, err
+}
+
 const verifyURI = "/verify"
 
 func (c *client) Verify(ctx context.Context, request Verify) error {
@@ -127,6 +178,7 @@ func (c *client) Clear(ctx context.Context, request ClearRequest) error {
 const resetURI = "/reset"
 
 func (c *client) Reset(ctx context.Context) error {
+	c.closeCallbacks()
 	return errors.Wrap(
 		c.do(ctx, resetURI, nil, nil),
 		"unable to reset all expectations",
@@ -143,3 +195,49 @@ func (c *client) Retrieve(ctx context.Context, request RetrieveRequest) (Retriev
 	)
 	return rs, err
 }
+
+func (c *client) RetrieveRequestResponses(ctx context.Context, request RetrieveRequest) ([]HTTPRequestAndResponse, error) {
+	var rs []HTTPRequestAndResponse
+	err := errors.Wrap(
+		c.do(ctx, retrieveTypeURI(RequestResponses), request, &rs),
+		"unable to retrieve requests and responses",
+	)
+	return rs, err
+}
+
+func (c *client) RetrieveActiveExpectations(ctx context.Context, request RetrieveRequest) ([]Expectation, error) {
+	var rs []Expectation
+	err := errors.Wrap(
+		c.do(ctx, retrieveTypeURI(ActiveExpectations), request, &rs),
+		"unable to retrieve active expectations",
+	)
+	return rs, err
+}
+
+func (c *client) RetrieveRecordedExpectations(ctx context.Context, request RetrieveRequest) ([]Expectation, error) {
+	var rs []Expectation
+	err := errors.Wrap(
+		c.do(ctx, retrieveTypeURI(RecordedExpectations), request, &rs),
+		"unable to retrieve recorded expectations",
+	)
+	return rs, err
+}
+
+func (c *client) RetrieveLogMessages(ctx context.Context, request RetrieveRequest) ([]string, error) {
+	body, err := c.doRaw(ctx, retrieveTypeURI(Logs), request)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve log messages")
+	}
+
+	var messages []string
+	for _, m := range strings.Split(string(body), LogMessageSeparator) {
+		if m = strings.TrimSpace(m); m != "" {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func retrieveTypeURI(t RetrieveType) string {
+	return fmt.Sprintf("%s?type=%s&format=JSON", retrieveURI, t)
+}
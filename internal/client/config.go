@@ -0,0 +1,129 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config describes how mock server app control plane is reached.
+type Config struct {
+	BaseURL string
+	Scheme  string
+	Host    string
+	Port    int
+	Verbose bool
+
+	HTTPClient *http.Client
+	Transport  http.RoundTripper
+	TLS        *TLSConfig
+	ProxyURL   string
+	Timeout    time.Duration
+}
+
+// TLSConfig contains PEM files for TLS connection, client certificate is used for mTLS.
+type TLSConfig struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// basePath returns URL of mock server app without trailing slash.
+func (cfg Config) basePath() (string, error) {
+	if cfg.BaseURL != "" {
+		u, err := url.Parse(cfg.BaseURL)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid base url %s", cfg.BaseURL)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return "", errors.Errorf("invalid base url %s: http(s) scheme and host are required", cfg.BaseURL)
+		}
+		return strings.TrimSuffix(cfg.BaseURL, "/"), nil
+	}
+
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	if scheme != "http" && scheme != "https" {
+		return "", errors.Errorf("unsupported scheme %s", scheme)
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, cfg.Host, cfg.Port), nil
+}
+
+// httpClient returns HTTPClient if it's set, otherwise the client is built by Transport (or default transport
+// with TLS and proxy settings) and Timeout.
+func (cfg Config) httpClient() (*http.Client, error) {
+	if cfg.HTTPClient != nil {
+		return cfg.HTTPClient, nil
+	}
+	if cfg.Transport == nil && cfg.TLS == nil && cfg.ProxyURL == "" && cfg.Timeout == 0 {
+		return http.DefaultClient, nil
+	}
+
+	transport := cfg.Transport
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.TLS != nil {
+			tlsConfig, err := cfg.TLS.load()
+			if err != nil {
+				return nil, err
+			}
+			t.TLSClientConfig = tlsConfig
+		}
+		if cfg.ProxyURL != "" {
+			proxy, err := url.Parse(cfg.ProxyURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid proxy url %s", cfg.ProxyURL)
+			}
+			t.Proxy = http.ProxyURL(proxy)
+		}
+		transport = t
+	}
+	return &http.Client{Transport: transport, Timeout: cfg.Timeout}, nil
+}
+
+func (cfg *TLSConfig) load() (*tls.Config, error) {
+	out := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		data, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read CA file")
+		}
+		out.RootCAs = x509.NewCertPool()
+		if !out.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load client certificate")
+		}
+		out.Certificates = []tls.Certificate{cert}
+	}
+	return out, nil
+}
+
+// httpTransport returns the client transport to be used by websocket connections, websocket can not be opened by
+// other RoundTripper implementations, so nil is returned for them and the connection is direct.
+func httpTransport(c *http.Client) *http.Transport {
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	t, _ := transport.(*http.Transport)
+	return t
+}
@@ -3,29 +3,84 @@ package client
 // Expectation
 
 type Expectation struct {
-	ID           string        `json:"id"`
-	Priority     int           `json:"priority"`
-	HTTPRequest  *HTTPRequest  `json:"httpRequest,omitempty"`
-	HTTPResponse *HTTPResponse `json:"httpResponse,omitempty"`
-	Times        *Times        `json:"times,omitempty"`
-	TimeToLive   *TimeToLive   `json:"timeToLive,omitempty"`
-	HTTPError    *HTTPError    `json:"httpError,omitempty"`
+	ID                           string                        `json:"id"`
+	Priority                     int                           `json:"priority"`
+	HTTPRequest                  *HTTPRequest                  `json:"httpRequest,omitempty"`
+	HTTPResponse                 *HTTPResponse                 `json:"httpResponse,omitempty"`
+	HTTPResponseTemplate         *HTTPResponseTemplate         `json:"httpResponseTemplate,omitempty"`
+	HTTPResponseObjectCallback   *HTTPObjectCallback           `json:"httpResponseObjectCallback,omitempty"`
+	HTTPForward                  *HTTPForward                  `json:"httpForward,omitempty"`
+	HTTPOverrideForwardedRequest *HTTPOverrideForwardedRequest `json:"httpOverrideForwardedRequest,omitempty"`
+	Times                        *Times                        `json:"times,omitempty"`
+	TimeToLive                   *TimeToLive                   `json:"timeToLive,omitempty"`
+	HTTPError                    *HTTPError                    `json:"httpError,omitempty"`
 }
 
 type HTTPRequest struct {
 	Method                string                 `json:"method"`
 	Path                  string                 `json:"path"`
 	PathParameters        map[string][]string    `json:"pathParameters,omitempty"`
-	QueryStringParameters map[string][]string    `json:"queryStringParameters,omitempty"`
+	QueryStringParameters map[string]interface{} `json:"queryStringParameters,omitempty"`
 	Headers               map[string]interface{} `json:"headers,omitempty"`
+	Cookies               map[string]string      `json:"cookies,omitempty"`
 	Body                  interface{}            `json:"body,omitempty"`
+	SocketAddress         *SocketAddress         `json:"socketAddress,omitempty"`
+	Not                   bool                   `json:"not,omitempty"`
 }
 
+type BodyType string
+
+const (
+	JSONBody       BodyType = "JSON"
+	JSONSchemaBody BodyType = "JSON_SCHEMA"
+	JSONPathBody   BodyType = "JSON_PATH"
+	XPathBody      BodyType = "XPATH"
+	XMLBody        BodyType = "XML"
+	RegexBody      BodyType = "REGEX"
+	StringBody     BodyType = "STRING"
+	BinaryBody     BodyType = "BINARY"
+)
+
+type MatchType string
+
+const (
+	Strict             MatchType = "STRICT"
+	OnlyMatchingFields MatchType = "ONLY_MATCHING_FIELDS"
+)
+
+// Body is typed body matcher, only fields related to the Type are set.
+type Body struct {
+	Type        BodyType    `json:"type"`
+	Not         bool        `json:"not,omitempty"`
+	JSON        interface{} `json:"json,omitempty"`
+	MatchType   MatchType   `json:"matchType,omitempty"`
+	JSONSchema  interface{} `json:"jsonSchema,omitempty"`
+	JSONPath    string      `json:"jsonPath,omitempty"`
+	XPath       string      `json:"xpath,omitempty"`
+	XML         string      `json:"xml,omitempty"`
+	Regex       string      `json:"regex,omitempty"`
+	String      string      `json:"string,omitempty"`
+	SubString   bool        `json:"subString,omitempty"`
+	Base64Bytes string      `json:"base64Bytes,omitempty"`
+	ContentType string      `json:"contentType,omitempty"`
+}
+
+type KeyMatchStyle string
+
+const (
+	SubSet      KeyMatchStyle = "SUB_SET"
+	MatchingKey KeyMatchStyle = "MATCHING_KEY"
+
+	// KeyMatchStyleKey is the key of headers or query parameters map which defines KeyMatchStyle of the map.
+	KeyMatchStyleKey = "keyMatchStyle"
+)
+
 type HTTPResponse struct {
 	Body         interface{}            `json:"body,omitempty"`
 	StatusCode   int                    `json:"statusCode"`
 	ReasonPhrase string                 `json:"reasonPhrase,omitempty"`
 	Headers      map[string]interface{} `json:"headers,omitempty"`
+	Cookies      map[string]string      `json:"cookies,omitempty"`
 	Delay        *Delay                 `json:"delay,omitempty"`
 }
 
@@ -35,6 +90,52 @@ type HTTPError struct {
 	ResponseBytes  string `json:"responseBytes,omitempty"`
 }
 
+type TemplateType string
+
+const (
+	VELOCITY   TemplateType = "VELOCITY"
+	MUSTACHE   TemplateType = "MUSTACHE"
+	JAVASCRIPT TemplateType = "JAVASCRIPT"
+)
+
+type HTTPResponseTemplate struct {
+	Template     string       `json:"template"`
+	TemplateType TemplateType `json:"templateType"`
+	Delay        *Delay       `json:"delay,omitempty"`
+}
+
+type HTTPObjectCallback struct {
+	ClientID         string `json:"clientId"`
+	ResponseCallback bool   `json:"responseCallback,omitempty"`
+	Delay            *Delay `json:"delay,omitempty"`
+}
+
+type Scheme string
+
+const (
+	HTTP  Scheme = "HTTP"
+	HTTPS Scheme = "HTTPS"
+)
+
+type HTTPForward struct {
+	Host   string `json:"host"`
+	Port   int    `json:"port,omitempty"`
+	Scheme Scheme `json:"scheme,omitempty"`
+	Delay  *Delay `json:"delay,omitempty"`
+}
+
+type HTTPOverrideForwardedRequest struct {
+	HTTPRequest  *HTTPRequest  `json:"httpRequest,omitempty"`
+	HTTPResponse *HTTPResponse `json:"httpResponse,omitempty"`
+	Delay        *Delay        `json:"delay,omitempty"`
+}
+
+type SocketAddress struct {
+	Host   string `json:"host"`
+	Port   int    `json:"port,omitempty"`
+	Scheme Scheme `json:"scheme,omitempty"`
+}
+
 type Times struct {
 	RemainingTimes int  `json:"remainingTimes"`
 	Unlimited      bool `json:"unlimited"`
@@ -63,10 +164,18 @@ type Delay struct {
 	Value    int      `json:"value"`
 }
 
+// OpenAPIExpectation creates expectations for operations of OpenAPI document, the operations are mapped to status code
+// of example response. All operations are created if OperationsAndResponses is empty. Mock server app selects the first
+// response of the operation if the status is empty or omitted, while in-memory mock server selects the lowest 2xx one.
+type OpenAPIExpectation struct {
+	SpecURLOrPayload       string            `json:"specUrlOrPayload"`
+	OperationsAndResponses map[string]string `json:"operationsAndResponses,omitempty"`
+}
+
 // Verify
 
 type Verify struct {
-	ExpectationID ExpectationID      `json:"expectationId"`
+	ExpectationID *ExpectationID     `json:"expectationId,omitempty"`
 	HTTPRequest   *HTTPRequest       `json:"httpRequest,omitempty"`
 	Times         *VerificationTimes `json:"times,omitempty"`
 }
@@ -75,6 +184,7 @@ type ExpectationID struct {
 	ID string `json:"id"`
 }
 
+// VerificationTimes limits number of matched requests, AtMost -1 means unlimited.
 type VerificationTimes struct {
 	AtLeast int `json:"atLeast"`
 	AtMost  int `json:"atMost"`
@@ -82,13 +192,40 @@ type VerificationTimes struct {
 
 type VerifySequence struct {
 	ExpectationIDs []ExpectationID `json:"expectationIds,omitempty"`
-	HTTPRequests   *HTTPRequest    `json:"httpRequest,omitempty"`
+	HTTPRequests   []HTTPRequest   `json:"httpRequests,omitempty"`
+}
+
+// VerificationError is returned by Verify and VerifySequence when mock server app didn't find the requests,
+// it contains the mismatch description sent by mock server app.
+type VerificationError string
+
+func (e VerificationError) Error() string {
+	return string(e)
 }
 
 type RetrieveRequest HTTPRequest
 
 type RetrieveResponse []HTTPRequest
 
+type RetrieveType string
+
+const (
+	Requests             RetrieveType = "REQUESTS"
+	RequestResponses     RetrieveType = "REQUEST_RESPONSES"
+	RecordedExpectations RetrieveType = "RECORDED_EXPECTATIONS"
+	ActiveExpectations   RetrieveType = "ACTIVE_EXPECTATIONS"
+	Logs                 RetrieveType = "LOGS"
+)
+
+type HTTPRequestAndResponse struct {
+	HTTPRequest  *HTTPRequest  `json:"httpRequest,omitempty"`
+	HTTPResponse *HTTPResponse `json:"httpResponse,omitempty"`
+	Timestamp    string        `json:"timestamp,omitempty"`
+}
+
+// LogMessageSeparator separates log messages returned by mock server app on retrieve of LOGS.
+const LogMessageSeparator = "------------------------------------"
+
 // Clear
 
 type ClearRequest struct {
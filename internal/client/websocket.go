@@ -0,0 +1,337 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// maxMessageSize limits size of the message received from mock server app, so malformed frame length
+	// can not exhaust memory
+	maxMessageSize = 32 << 20
+)
+
+// websocket is minimal RFC 6455 client connection which is enough to exchange text messages with mock server app.
+type websocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// dialWebsocket opens websocket connection by http(s) URL, the connection keeps context deadline until resetDeadline.
+// Dialer, proxy and TLS config of the transport are used, nil transport means direct connection.
+func dialWebsocket(ctx context.Context, rawURL string, headers http.Header, transport *http.Transport) (*websocket, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid websocket url")
+	}
+	if transport == nil {
+		transport = &http.Transport{}
+	}
+
+	conn, err := dial(ctx, u, transport)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to dial websocket")
+	}
+
+	ws, err := handshake(ctx, conn, u, headers)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
+
+// dial connects to the host of the URL directly or by CONNECT tunnel of http proxy, TLS handshake is done for https.
+func dial(ctx context.Context, u *url.URL, transport *http.Transport) (net.Conn, error) {
+	secure := u.Scheme == "https"
+	address := hostPort(u)
+	dialContext := transport.DialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+
+	var proxy *url.URL
+	if transport.Proxy != nil {
+		var err error
+		if proxy, err = transport.Proxy(&http.Request{URL: u}); err != nil {
+			return nil, errors.Wrap(err, "unable to get proxy")
+		}
+	}
+
+	var conn net.Conn
+	var err error
+	switch {
+	case proxy == nil:
+		conn, err = dialContext(ctx, "tcp", address)
+	case proxy.Scheme != "http":
+		return nil, errors.Errorf("proxy scheme %s is not supported by websocket, only http proxy is", proxy.Scheme)
+	default:
+		if conn, err = dialContext(ctx, "tcp", hostPort(proxy)); err == nil {
+			err = connect(ctx, conn, address, proxy)
+		}
+	}
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, err
+	}
+	if !secure {
+		return conn, nil
+	}
+
+	config := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		config = transport.TLSClientConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = u.Hostname()
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "tls handshake failed")
+	}
+	return tlsConn, nil
+}
+
+// connect opens tunnel to the address by CONNECT request to http proxy.
+func connect(ctx context.Context, conn net.Conn, address string, proxy *url.URL) error {
+	rq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		rq.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if err := rq.Write(conn); err != nil {
+		return errors.Wrap(err, "unable to send proxy CONNECT request")
+	}
+	// the proxy doesn't send anything before the tunnel is used, so nothing is lost in the buffer
+	rs, err := http.ReadResponse(bufio.NewReader(conn), rq)
+	if err != nil {
+		return errors.Wrap(err, "unable to read proxy CONNECT response")
+	}
+	rs.Body.Close()
+	if rs.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected proxy CONNECT status %d instead of 200", rs.StatusCode)
+	}
+	return nil
+}
+
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
+
+func handshake(ctx context.Context, conn net.Conn, u *url.URL, headers http.Header) (*websocket, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "unable to generate websocket key")
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	rq, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+u.Host+u.RequestURI(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to prepare websocket handshake")
+	}
+	for k, v := range headers {
+		rq.Header[k] = v
+	}
+	rq.Header.Set("Upgrade", "websocket")
+	rq.Header.Set("Connection", "Upgrade")
+	rq.Header.Set("Sec-WebSocket-Key", key)
+	rq.Header.Set("Sec-WebSocket-Version", "13")
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if err := rq.Write(conn); err != nil {
+		return nil, errors.Wrap(err, "unable to send websocket handshake")
+	}
+
+	reader := bufio.NewReader(conn)
+	rs, err := http.ReadResponse(reader, rq)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read websocket handshake")
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode != http.StatusSwitchingProtocols {
+		return nil, errors.Errorf("unexpected websocket handshake status %d instead of 101", rs.StatusCode)
+	}
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	if rs.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(accept[:]) {
+		return nil, errors.New("invalid websocket handshake accept key")
+	}
+
+	return &websocket{conn: conn, reader: reader}, nil
+}
+
+// read returns the next text message, control frames are handled internally.
+// The message larger than maxMessageSize is an error.
+func (ws *websocket) read() ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := ws.readFrame(maxMessageSize - uint64(len(message)))
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case opText, opContinuation:
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opClose:
+			_ = ws.writeFrame(opClose, payload)
+			return nil, io.EOF
+		}
+	}
+}
+
+// resetDeadline removes deadline which was set during handshake from context.
+func (ws *websocket) resetDeadline() {
+	_ = ws.conn.SetDeadline(time.Time{})
+}
+
+func (ws *websocket) write(message []byte) error {
+	return ws.writeFrame(opText, message)
+}
+
+func (ws *websocket) close() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		return nil
+	}
+	ws.closed = true
+	_ = ws.writeFrameLocked(opClose, nil)
+	return ws.conn.Close()
+}
+
+// readFrame reads single frame, the frame with payload longer than limit is an error.
+func (ws *websocket) readFrame(limit uint64) (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(ws.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(ws.reader, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(ws.reader, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if length > limit {
+		return false, 0, nil, errors.Errorf("websocket message exceeds %d bytes", maxMessageSize)
+	}
+
+	mask := make([]byte, 4)
+	if masked {
+		if _, err := io.ReadFull(ws.reader, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+func (ws *websocket) writeFrame(opcode byte, payload []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		return errors.New("websocket is closed")
+	}
+	return ws.writeFrameLocked(opcode, payload)
+}
+
+// writeFrameLocked writes single masked frame as it's required for client frames.
+func (ws *websocket) writeFrameLocked(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	length := len(payload)
+	switch {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return errors.Wrap(err, "unable to generate websocket mask")
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := ws.conn.Write(frame)
+	return errors.Wrap(err, "unable to write websocket frame")
+}
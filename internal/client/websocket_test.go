@@ -0,0 +1,374 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frame is websocket frame read by the test server.
+type frame struct {
+	fin     bool
+	opcode  byte
+	masked  bool
+	payload []byte
+}
+
+// callbackServer is mock server app callback websocket endpoint, the serve function talks to the connected client.
+func callbackServer(t *testing.T, secure bool, acceptKey func(string) string, serve func(*bufio.ReadWriter)) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, callbackURI, r.URL.Path)
+		assert.NotEmpty(t, r.Header.Get(registrationIDHeader))
+		assert.Equal(t, "websocket", r.Header.Get("Upgrade"))
+		assert.Equal(t, "13", r.Header.Get("Sec-WebSocket-Version"))
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+			"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		require.NoError(t, rw.Flush())
+		serve(rw)
+	}))
+	// handshake errors of not trusted certificate are expected
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	if secure {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func validAcceptKey(key string) string {
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(accept[:])
+}
+
+func writeServerFrame(t *testing.T, w *bufio.ReadWriter, fin bool, opcode byte, payload []byte, mask []byte) {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	header := []byte{first, 0}
+	switch {
+	case len(payload) < 126:
+		header[1] = byte(len(payload))
+	case len(payload) <= 0xFFFF:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(len(payload)))
+	default:
+		header[1] = 127
+		header = append(header, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(header[2:], uint64(len(payload)))
+	}
+	data := append([]byte{}, payload...)
+	if mask != nil {
+		header[1] |= 0x80
+		header = append(header, mask...)
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	_, err := w.Write(append(header, data...))
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+}
+
+func readClientFrame(t *testing.T, r *bufio.ReadWriter) frame {
+	ws := &websocket{reader: r.Reader}
+	peek, err := r.Peek(2)
+	require.NoError(t, err)
+	f := frame{masked: peek[1]&0x80 != 0}
+	f.fin, f.opcode, f.payload, err = ws.readFrame(maxMessageSize)
+	require.NoError(t, err)
+	return f
+}
+
+func writeMessage(t *testing.T, w *bufio.ReadWriter, messageType string, value interface{}) {
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	message, err := json.Marshal(callbackMessage{Type: messageType, Value: string(data)})
+	require.NoError(t, err)
+	writeServerFrame(t, w, true, opText, message, nil)
+}
+
+func readResponse(t *testing.T, r *bufio.ReadWriter) (frame, HTTPResponse) {
+	f := readClientFrame(t, r)
+	require.Equal(t, byte(opText), f.opcode)
+	message := callbackMessage{}
+	require.NoError(t, json.Unmarshal(f.payload, &message))
+	require.Equal(t, responseMessageType, message.Type)
+	rs := HTTPResponse{}
+	require.NoError(t, json.Unmarshal([]byte(message.Value), &rs))
+	return f, rs
+}
+
+func TestCallbackHandshake(t *testing.T) {
+	for name, tc := range map[string]struct {
+		acceptKey func(string) string
+		err       string
+	}{
+		"valid accept key":   {acceptKey: validAcceptKey},
+		"invalid accept key": {acceptKey: func(string) string { return "invalid" }, err: "invalid websocket handshake accept key"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := callbackServer(t, false, tc.acceptKey, func(rw *bufio.ReadWriter) {
+				writeMessage(t, rw, clientIDMessageType, callbackClientID{ClientID: "some-client-id"})
+			})
+			c := NewClient(Config{BaseURL: server.URL})
+			defer c.closeCallbacks()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			id, err := c.Callback(ctx, func(HTTPRequest) HTTPResponse { return HTTPResponse{} })
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "some-client-id", id)
+		})
+	}
+}
+
+func TestCallbackRoundTrip(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	done := make(chan struct{})
+	server := callbackServer(t, false, validAcceptKey, func(rw *bufio.ReadWriter) {
+		defer close(done)
+		writeMessage(t, rw, clientIDMessageType, callbackClientID{ClientID: "some-client-id"})
+
+		// ping is answered by pong with the same payload
+		writeServerFrame(t, rw, true, opPing, []byte("ping"), nil)
+		f := readClientFrame(t, rw)
+		assert.Equal(t, byte(opPong), f.opcode)
+		assert.True(t, f.masked)
+		assert.Equal(t, "ping", string(f.payload))
+
+		// fragmented and masked request with body which requires 16 bit length of the response frame
+		body := strings.Repeat("a", 1000)
+		data, err := json.Marshal(HTTPRequest{
+			Method:  http.MethodPost,
+			Path:    "/echo",
+			Headers: map[string]interface{}{correlationIDHeader: []string{"correlation-1"}},
+			Body:    body,
+		})
+		require.NoError(t, err)
+		message, err := json.Marshal(callbackMessage{Type: requestMessageType, Value: string(data)})
+		require.NoError(t, err)
+		writeServerFrame(t, rw, false, opText, message[:10], []byte{1, 2, 3, 4})
+		writeServerFrame(t, rw, true, opContinuation, message[10:], nil)
+
+		f, rs := readResponse(t, rw)
+		assert.True(t, f.masked)
+		assert.Equal(t, http.StatusOK, rs.StatusCode)
+		assert.Equal(t, body, rs.Body)
+		assert.Equal(t, []interface{}{"correlation-1"}, rs.Headers[correlationIDHeader])
+
+		// request with body which requires 64 bit length of the frame
+		body = strings.Repeat("b", 70000)
+		writeMessage(t, rw, requestMessageType, HTTPRequest{
+			Method:  http.MethodPost,
+			Path:    "/echo",
+			Headers: map[string]interface{}{correlationIDHeader: []string{"correlation-2"}},
+			Body:    body,
+		})
+		_, rs = readResponse(t, rw)
+		assert.Equal(t, body, rs.Body)
+		assert.Equal(t, []interface{}{"correlation-2"}, rs.Headers[correlationIDHeader])
+
+		// panic of the handler is turned into 500 response
+		writeMessage(t, rw, requestMessageType, HTTPRequest{
+			Method:  http.MethodGet,
+			Path:    "/panic",
+			Headers: map[string]interface{}{correlationIDHeader: []string{"correlation-3"}},
+		})
+		_, rs = readResponse(t, rw)
+		assert.Equal(t, http.StatusInternalServerError, rs.StatusCode)
+		assert.Equal(t, "callback panic: some panic", rs.Body)
+		assert.Equal(t, []interface{}{"correlation-3"}, rs.Headers[correlationIDHeader])
+
+		// close frame is answered by close frame
+		writeServerFrame(t, rw, true, opClose, nil, nil)
+		f = readClientFrame(t, rw)
+		assert.Equal(t, byte(opClose), f.opcode)
+	})
+
+	c := NewClient(Config{BaseURL: server.URL})
+	defer c.closeCallbacks()
+	_, err := c.Callback(context.Background(), func(rq HTTPRequest) HTTPResponse {
+		if rq.Path == "/panic" {
+			panic("some panic")
+		}
+		return HTTPResponse{StatusCode: http.StatusOK, Body: rq.Body}
+	})
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback exchange is not completed")
+	}
+}
+
+func TestCallbackMessageLimit(t *testing.T) {
+	// frame header declaring the payload length, the payload itself is never sent
+	header := func(fin bool, opcode byte, length uint64) []byte {
+		if fin {
+			opcode |= 0x80
+		}
+		h := []byte{opcode, 127, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint64(h[2:], length)
+		return h
+	}
+	for name, tc := range map[string]struct {
+		frames [][]byte
+		err    string
+	}{
+		"frame length": {
+			frames: [][]byte{header(true, opText, 1<<63)},
+			err:    "websocket message exceeds 33554432 bytes",
+		},
+		"fragmented message": {
+			frames: [][]byte{{opText, 3, 'a', 'b', 'c'}, header(true, opContinuation, maxMessageSize-2)},
+			err:    "websocket message exceeds 33554432 bytes",
+		},
+		"message within the limit": {
+			frames: [][]byte{{opText, 3, 'a', 'b', 'c'}, {0x80 | opContinuation, 1, 'd'}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ws := &websocket{reader: bufio.NewReader(bytes.NewReader(bytes.Join(tc.frames, nil)))}
+			message, err := ws.read()
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "abcd", string(message))
+		})
+	}
+}
+
+func TestCloseCallback(t *testing.T) {
+	closed := make(chan frame, 1)
+	server := callbackServer(t, false, validAcceptKey, func(rw *bufio.ReadWriter) {
+		writeMessage(t, rw, clientIDMessageType, callbackClientID{ClientID: "some-client-id"})
+		closed <- readClientFrame(t, rw)
+	})
+	c := NewClient(Config{BaseURL: server.URL})
+	defer c.closeCallbacks()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	id, err := c.Callback(ctx, func(HTTPRequest) HTTPResponse { return HTTPResponse{} })
+	require.NoError(t, err)
+
+	// unknown client id is ignored
+	c.CloseCallback("other-client-id")
+	c.CloseCallback(id)
+	select {
+	case f := <-closed:
+		assert.Equal(t, byte(opClose), f.opcode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback websocket is not closed")
+	}
+	assert.Empty(t, c.callbacks)
+}
+
+// connectProxy is http proxy which tunnels CONNECT requests, the addresses of the tunnels are sent to the channel.
+func connectProxy(t *testing.T, tunnels chan<- string) *httptest.Server {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		tunnels <- r.Host
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer target.Close()
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 200 Connection established\r\n\r\n")
+		_ = rw.Flush()
+
+		go func() {
+			_, _ = io.Copy(target, rw)
+		}()
+		_, _ = io.Copy(conn, target)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+func TestCallbackProxy(t *testing.T) {
+	server := callbackServer(t, false, validAcceptKey, func(rw *bufio.ReadWriter) {
+		writeMessage(t, rw, clientIDMessageType, callbackClientID{ClientID: "some-client-id"})
+	})
+	tunnels := make(chan string, 1)
+	proxy := connectProxy(t, tunnels)
+
+	c := NewClient(Config{BaseURL: server.URL, ProxyURL: proxy.URL})
+	defer c.closeCallbacks()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	id, err := c.Callback(ctx, func(HTTPRequest) HTTPResponse { return HTTPResponse{} })
+	require.NoError(t, err)
+	assert.Equal(t, "some-client-id", id)
+	assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), <-tunnels)
+
+	c = NewClient(Config{BaseURL: server.URL, ProxyURL: "socks5://" + strings.TrimPrefix(proxy.URL, "http://")})
+	_, err = c.Callback(ctx, func(HTTPRequest) HTTPResponse { return HTTPResponse{} })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "proxy scheme socks5 is not supported by websocket")
+}
+
+func TestCallbackTLS(t *testing.T) {
+	server := callbackServer(t, true, validAcceptKey, func(rw *bufio.ReadWriter) {
+		writeMessage(t, rw, clientIDMessageType, callbackClientID{ClientID: "some-client-id"})
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c := NewClient(Config{BaseURL: server.URL, HTTPClient: server.Client()})
+	defer c.closeCallbacks()
+	id, err := c.Callback(ctx, func(HTTPRequest) HTTPResponse { return HTTPResponse{} })
+	require.NoError(t, err)
+	assert.Equal(t, "some-client-id", id)
+
+	// the server certificate isn't trusted by default TLS config
+	c = NewClient(Config{BaseURL: server.URL, Timeout: time.Second})
+	_, err = c.Callback(ctx, func(HTTPRequest) HTTPResponse { return HTTPResponse{} })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tls handshake failed")
+}
@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	reset = "\x1b[0m"
+)
+
+// Unified returns line diff of expected and actual values in unified format with full context, JSON values are
+// pretty printed with sorted keys, so only meaningful differences are shown. Empty string is returned if there
+// is no difference.
+func Unified(expected, actual string) string {
+	return unified(expected, actual, false)
+}
+
+// Colored returns the same diff as Unified does with removed lines in red and added lines in green.
+func Colored(expected, actual string) string {
+	return unified(expected, actual, true)
+}
+
+func unified(expected, actual string, color bool) string {
+	expected, actual = prettyJSON(expected), prettyJSON(actual)
+	if expected == actual {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("--- expected\n+++ actual\n")
+	for _, l := range lines(strings.Split(expected, "\n"), strings.Split(actual, "\n")) {
+		switch {
+		case color && l[0] == '-':
+			b.WriteString(red + l + reset)
+		case color && l[0] == '+':
+			b.WriteString(green + l + reset)
+		default:
+			b.WriteString(l)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// maxCells limits the size of the table of the longest common subsequence, the changed lines of larger inputs
+// are shown as removed and added entirely to keep memory usage bounded.
+const maxCells = 1 << 20
+
+// lines finds the longest common subsequence of the lines and marks the rest as removed or added, common prefix
+// and suffix are trimmed before the table is built.
+func lines(a, b []string) []string {
+	out := make([]string, 0, len(a)+len(b))
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		out = append(out, " "+a[prefix])
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	out = append(out, changed(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		out = append(out, " "+l)
+	}
+	return out
+}
+
+func changed(a, b []string) []string {
+	out := make([]string, 0, len(a)+len(b))
+	if (len(a)+1)*(len(b)+1) > maxCells {
+		for _, l := range a {
+			out = append(out, "-"+l)
+		}
+		for _, l := range b {
+			out = append(out, "+"+l)
+		}
+		return out
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "-"+a[i])
+			i++
+		default:
+			out = append(out, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+"+b[j])
+	}
+	return out
+}
+
+func prettyJSON(s string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return s
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
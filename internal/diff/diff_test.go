@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	for name, tc := range map[string]struct {
+		expected string
+		actual   string
+		diff     string
+	}{
+		"empty inputs": {},
+		"identical inputs": {
+			expected: "a\nb",
+			actual:   "a\nb",
+		},
+		"equal json with different formatting": {
+			expected: `{"b":1,"a":[1,2]}`,
+			actual:   "{\n\"a\": [1, 2],\n\"b\": 1\n}",
+		},
+		"empty expected": {
+			actual: "a",
+			diff:   "--- expected\n+++ actual\n-\n+a",
+		},
+		"empty actual": {
+			expected: "a",
+			diff:     "--- expected\n+++ actual\n-a\n+",
+		},
+		"changed line": {
+			expected: "a\nb\nc",
+			actual:   "a\nx\nc",
+			diff:     "--- expected\n+++ actual\n a\n-b\n+x\n c",
+		},
+		"added and removed lines": {
+			expected: "a\nb\nc\nd",
+			actual:   "b\nc\ne\nd",
+			diff:     "--- expected\n+++ actual\n-a\n b\n c\n+e\n d",
+		},
+		"json field": {
+			expected: `{"id":1,"name":"Rex"}`,
+			actual:   `{"name":"Tom","id":1}`,
+			diff:     "--- expected\n+++ actual\n {\n   \"id\": 1,\n-  \"name\": \"Rex\"\n+  \"name\": \"Tom\"\n }",
+		},
+		"invalid json is compared as text": {
+			expected: `{"id":1`,
+			actual:   `{"id":2`,
+			diff:     "--- expected\n+++ actual\n-{\"id\":1\n+{\"id\":2",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.diff, Unified(tc.expected, tc.actual))
+		})
+	}
+}
+
+func TestColored(t *testing.T) {
+	assert.Equal(t, "--- expected\n+++ actual\n a\n"+red+"-b"+reset+"\n"+green+"+c"+reset, Colored("a\nb", "a\nc"))
+	assert.Empty(t, Colored("a", "a"))
+}
+
+func TestUnifiedLargeInput(t *testing.T) {
+	var expected, actual []string
+	for i := 0; i < 2000; i++ {
+		expected = append(expected, "e"+strconv.Itoa(i))
+		actual = append(actual, "a"+strconv.Itoa(i))
+	}
+	d := Unified("head\n"+strings.Join(expected, "\n")+"\ntail", "head\n"+strings.Join(actual, "\n")+"\ntail")
+
+	out := strings.Split(d, "\n")
+	assert.Len(t, out, 2+1+2000+2000+1)
+	assert.Equal(t, " head", out[2])
+	assert.Equal(t, "-e0", out[3])
+	assert.Equal(t, "+a0", out[2003])
+	assert.Equal(t, " tail", out[len(out)-1])
+}
@@ -0,0 +1,183 @@
+package inmemory
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+var forwardClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func writeForward(w http.ResponseWriter, r *http.Request, rq *recordedRequest, f *client.HTTPForward) {
+	if !wait(r.Context(), f.Delay) {
+		return
+	}
+	headers := rq.headers.Clone()
+	headers.Del("Host")
+	target := forwardURL(&client.SocketAddress{Host: f.Host, Port: f.Port, Scheme: f.Scheme}, rq.path, rq.query)
+	proxy(w, r, target, headers, rq.body, nil)
+}
+
+func writeOverrideForward(w http.ResponseWriter, r *http.Request, rq *recordedRequest, f *client.HTTPOverrideForwardedRequest) {
+	if !wait(r.Context(), f.Delay) {
+		return
+	}
+
+	override := f.HTTPRequest
+	if override == nil {
+		override = &client.HTTPRequest{}
+	}
+
+	headers := rq.headers.Clone()
+	headers.Del("Host")
+	for k, v := range override.Headers {
+		headers[http.CanonicalHeaderKey(k)] = client.Values(v)
+	}
+
+	query := url.Values{}
+	for k, v := range rq.query {
+		query[k] = v
+	}
+	for k, v := range override.QueryStringParameters {
+		query[k] = client.Values(v)
+	}
+
+	path := rq.path
+	if override.Path != "" {
+		path = override.Path
+	}
+
+	body := rq.body
+	if override.Body != nil {
+		body, _ = client.BodyBytes(override.Body)
+	}
+
+	socketAddress := override.SocketAddress
+	if socketAddress == nil {
+		socketAddress = &client.SocketAddress{Host: headers.Get("Host")}
+	}
+
+	proxy(w, r, forwardURL(socketAddress, path, query), headers, body, f.HTTPResponse)
+}
+
+// writeProxy proxies the request to the remote and records the exchange as an expectation.
+func (s *Server) writeProxy(w *responseRecorder, r *http.Request, rq *recordedRequest) {
+	headers := rq.headers.Clone()
+	headers.Del("Host")
+	target := &url.URL{
+		Scheme:   s.remote.Scheme,
+		Host:     s.remote.Host,
+		Path:     rq.path,
+		RawQuery: rq.query.Encode(),
+	}
+	if !proxy(w, r, target, headers, rq.body, nil) {
+		return
+	}
+
+	rs := w.response()
+	if rs == nil {
+		return
+	}
+	recordedRequest := rq.toClient()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recorded = append(s.recorded, recordedExpectation{
+		Expectation: client.Expectation{
+			ID:           uuid.NewString(),
+			HTTPRequest:  &recordedRequest,
+			HTTPResponse: rs.toClient(),
+			Times:        &client.Times{RemainingTimes: 1},
+			TimeToLive:   &client.TimeToLive{Unlimited: true},
+		},
+		request: rq,
+	})
+	s.logf("returning response:\n\n  %s\n\n for forwarded request:\n\n  %s",
+		toJSON(rs.toClient()), toJSON(recordedRequest))
+}
+
+// proxy sends the request to the target, the response is written back with overridden fields if any.
+// When target is unreachable 404 status is returned the same way as mock server app does and false is returned.
+func proxy(w http.ResponseWriter, r *http.Request, target *url.URL, headers http.Header, body []byte, override *client.HTTPResponse) bool {
+	rq, err := http.NewRequestWithContext(r.Context(), r.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+	for k, v := range headers {
+		switch k {
+		case "Host":
+			rq.Host = headers.Get("Host")
+		case "Content-Length":
+		default:
+			rq.Header[k] = v
+		}
+	}
+
+	rs, err := forwardClient.Do(rq)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+	defer rs.Body.Close()
+
+	data, err := ioutil.ReadAll(rs.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+
+	status := rs.StatusCode
+	for k, v := range rs.Header {
+		if k != "Content-Length" {
+			w.Header()[k] = v
+		}
+	}
+	if override != nil {
+		for k, v := range override.Headers {
+			w.Header()[http.CanonicalHeaderKey(k)] = client.Values(v)
+		}
+		if override.Body != nil {
+			data, _ = client.BodyBytes(override.Body)
+		}
+		if override.StatusCode != 0 {
+			status = override.StatusCode
+		}
+	}
+
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+	return true
+}
+
+func forwardURL(address *client.SocketAddress, path string, query url.Values) *url.URL {
+	scheme := "http"
+	if address.Scheme == client.HTTPS {
+		scheme = "https"
+	}
+	host := address.Host
+	if address.Port != 0 {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = net.JoinHostPort(host, strconv.Itoa(address.Port))
+	}
+	return &url.URL{
+		Scheme:   scheme,
+		Host:     host,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+}
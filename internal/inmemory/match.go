@@ -0,0 +1,382 @@
+package inmemory
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/jsonpath"
+	"github.com/YReshetko/mock-server-client/internal/jsonschema"
+)
+
+const (
+	notPrefix      = "!"
+	optionalPrefix = "?"
+)
+
+var pathParameterPattern = regexp.MustCompile(`\{([^/{}]+)}`)
+
+// MatchRecorded checks the request recorded by mock server app against the request matcher the same way in-memory
+// mock server matches received requests, so recorded requests can be matched on the client side. Each field of
+// the matcher is described as mock server app does, for example "method matched" or "path didn't match".
+// The request matcher has to be Supported.
+func MatchRecorded(matcher, recorded client.HTTPRequest) (bool, []string) {
+	// body matchers are received as JSON objects on the wire
+	m := client.HTTPRequest{}
+	if err := normalize(matcher, &m); err != nil {
+		return false, nil
+	}
+	r := fromClient(recorded)
+	return matchRequest(&m, r), mismatchReasons(&m, r)
+}
+
+// Supported checks if the request matcher can be matched by in-memory mock server.
+func Supported(matcher client.HTTPRequest) bool {
+	m := client.HTTPRequest{}
+	return normalize(matcher, &m) == nil && unsupported(&m) == nil
+}
+
+// matchRequest checks if recorded request satisfies the request matcher, nil matcher matches any request.
+func matchRequest(m *client.HTTPRequest, r *recordedRequest) bool {
+	if m == nil {
+		return true
+	}
+	matched := true
+	for _, f := range requestFields {
+		if !f.match(m, r) {
+			matched = false
+			break
+		}
+	}
+	return matched != m.Not
+}
+
+// mismatchReasons describes each field of the request matcher the same way as mock server app does, for example
+// "method matched" or "path didn't match".
+func mismatchReasons(m *client.HTTPRequest, r *recordedRequest) []string {
+	if m == nil {
+		return nil
+	}
+	reasons := make([]string, len(requestFields))
+	for i, f := range requestFields {
+		if f.match(m, r) {
+			reasons[i] = f.name + " matched"
+		} else {
+			reasons[i] = f.name + " didn't match"
+		}
+	}
+	return reasons
+}
+
+// requestFields are matched in the order mock server app reports them.
+var requestFields = []struct {
+	name  string
+	match func(*client.HTTPRequest, *recordedRequest) bool
+}{
+	{"method", func(m *client.HTTPRequest, r *recordedRequest) bool {
+		return matchString(m.Method, r.method)
+	}},
+	{"path", func(m *client.HTTPRequest, r *recordedRequest) bool {
+		return matchPath(m.Path, m.PathParameters, r.path)
+	}},
+	{"queryParameters", func(m *client.HTTPRequest, r *recordedRequest) bool {
+		return matchMultiValues(m.QueryStringParameters, func(k string) ([]string, bool) {
+			v, ok := r.query[k]
+			return v, ok
+		})
+	}},
+	{"headers", func(m *client.HTTPRequest, r *recordedRequest) bool {
+		return matchMultiValues(m.Headers, func(k string) ([]string, bool) {
+			v, ok := r.headers[http.CanonicalHeaderKey(k)]
+			return v, ok
+		})
+	}},
+	{"cookies", func(m *client.HTTPRequest, r *recordedRequest) bool {
+		return matchCookies(m.Cookies, r.cookies())
+	}},
+	{"body", func(m *client.HTTPRequest, r *recordedRequest) bool {
+		return matchBody(m.Body, r)
+	}},
+}
+
+// matchString checks exact match or full match of the matcher as regular expression, empty matcher matches anything.
+// The matcher prefixed by ! matches anything except the rest of the matcher.
+func matchString(matcher, actual string) bool {
+	if strings.HasPrefix(matcher, notPrefix) {
+		return !matchString(matcher[len(notPrefix):], actual)
+	}
+	if matcher == "" || matcher == actual {
+		return true
+	}
+	r, err := regexp.Compile("^(?:" + matcher + ")$")
+	if err != nil {
+		return false
+	}
+	return r.MatchString(actual)
+}
+
+func matchPath(matcher string, params map[string][]string, actual string) bool {
+	if strings.HasPrefix(matcher, notPrefix) {
+		return !matchPath(matcher[len(notPrefix):], params, actual)
+	}
+	if !pathParameterPattern.MatchString(matcher) {
+		return matchString(matcher, actual)
+	}
+	actualParams, ok := PathParameters(matcher, actual)
+	return ok && matchValues(params, actualParams)
+}
+
+// PathParameters extracts path parameters from actual path by path matcher, for example "/pets/{pet_id}".
+// It returns false if the path doesn't match the matcher.
+func PathParameters(matcher, actual string) (map[string][]string, bool) {
+	var names []string
+	pattern := strings.Builder{}
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range pathParameterPattern.FindAllStringSubmatchIndex(matcher, -1) {
+		pattern.WriteString(regexp.QuoteMeta(matcher[last:loc[0]]))
+		pattern.WriteString("([^/]+)")
+		names = append(names, matcher[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(matcher[last:]))
+	pattern.WriteString("$")
+
+	groups := regexp.MustCompile(pattern.String()).FindStringSubmatch(actual)
+	if groups == nil {
+		return nil, false
+	}
+	params := url.Values{}
+	for i, name := range names {
+		params.Add(name, groups[i+1])
+	}
+	return params, true
+}
+
+// matchValues checks that each matcher key is present and each matcher value matches at least one actual value.
+func matchValues(matcher map[string][]string, actual map[string][]string) bool {
+	m := map[string]interface{}{}
+	for k, v := range matcher {
+		m[k] = v
+	}
+	return matchMultiValues(m, func(k string) ([]string, bool) {
+		v, ok := actual[k]
+		return v, ok
+	})
+}
+
+func matchCookies(matcher map[string]string, actual map[string]string) bool {
+	m := map[string]interface{}{}
+	for k, v := range matcher {
+		m[k] = v
+	}
+	return matchMultiValues(m, func(k string) ([]string, bool) {
+		v, ok := actual[k]
+		return []string{v}, ok
+	})
+}
+
+// matchMultiValues checks headers or parameters by the key match style of the matcher:
+// SUB_SET (default) requires each matcher value matches at least one actual value,
+// MATCHING_KEY requires each actual value matches at least one matcher value.
+// The key prefixed by ! requires that there is no such key with matching values,
+// the key prefixed by ? is checked only if it's present.
+func matchMultiValues(matcher map[string]interface{}, lookup func(string) ([]string, bool)) bool {
+	style, _ := matcher[client.KeyMatchStyleKey].(string)
+	for key, values := range matcher {
+		if key == client.KeyMatchStyleKey {
+			continue
+		}
+		if !matchKey(key, client.Values(values), client.KeyMatchStyle(style), lookup) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchKey(key string, values []string, style client.KeyMatchStyle, lookup func(string) ([]string, bool)) bool {
+	negated := strings.HasPrefix(key, notPrefix)
+	if negated {
+		key = key[len(notPrefix):]
+	}
+	optional := strings.HasPrefix(key, optionalPrefix)
+	if optional {
+		key = key[len(optionalPrefix):]
+	}
+
+	actualValues, ok := lookup(key)
+	if optional && !ok {
+		return true
+	}
+	matched := ok && matchAll(values, actualValues)
+	if style == client.MatchingKey {
+		matched = ok && matchEach(values, actualValues)
+	}
+	return matched != negated
+}
+
+func matchAll(matchers []string, actual []string) bool {
+	for _, m := range matchers {
+		if !matchAny(m, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchEach checks that each actual value matches at least one matcher.
+func matchEach(matchers []string, actual []string) bool {
+	for _, a := range actual {
+		matched := false
+		for _, m := range matchers {
+			if matchString(m, a) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(matcher string, actual []string) bool {
+	for _, a := range actual {
+		if matchString(matcher, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchBody(matcher interface{}, r *recordedRequest) bool {
+	switch m := matcher.(type) {
+	case nil:
+		return true
+	case string:
+		return m == string(r.body)
+	case map[string]interface{}:
+		if _, ok := m["type"]; ok {
+			return matchTypedBody(m, r)
+		}
+	}
+	return matchJSON(matcher, r.body, false)
+}
+
+func matchTypedBody(m map[string]interface{}, r *recordedRequest) bool {
+	not, _ := m["not"].(bool)
+	return matchBodyType(m, r) != not
+}
+
+func matchBodyType(m map[string]interface{}, r *recordedRequest) bool {
+	switch m["type"] {
+	case "JSON":
+		return matchJSON(m["json"], r.body, m["matchType"] == "STRICT")
+	case "STRING":
+		s := stringValue(m["string"])
+		if subString, _ := m["subString"].(bool); subString {
+			return strings.Contains(string(r.body), s)
+		}
+		return s == string(r.body)
+	case "JSON_SCHEMA":
+		return matchJSONSchema(m["jsonSchema"], r.body)
+	case "JSON_PATH":
+		nodes, err := jsonpath.EvaluateJSON(stringValue(m["jsonPath"]), r.body)
+		return err == nil && len(nodes) > 0
+	case "XML":
+		return equalXML(stringValue(m["xml"]), string(r.body))
+	case "REGEX":
+		return matchString(stringValue(m["regex"]), string(r.body))
+	case "BINARY":
+		data, err := base64.StdEncoding.DecodeString(stringValue(m["base64Bytes"]))
+		return err == nil && bytes.Equal(data, r.body)
+	case "PARAMETERS":
+		params := map[string][]string{}
+		if err := normalize(m["parameters"], &params); err != nil {
+			return false
+		}
+		actual, err := url.ParseQuery(string(r.body))
+		return err == nil && matchValues(params, actual)
+	default:
+		return false
+	}
+}
+
+func matchJSONSchema(schema interface{}, body []byte) bool {
+	if s, ok := schema.(string); ok {
+		if err := json.Unmarshal([]byte(s), &schema); err != nil {
+			return false
+		}
+	}
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return false
+	}
+	return len(jsonschema.Validate(schema, actual)) == 0
+}
+
+// matchJSON compares expected JSON with actual body, not strict comparison allows extra fields and any array order.
+func matchJSON(expected interface{}, body []byte, strict bool) bool {
+	if s, ok := expected.(string); ok {
+		if err := json.Unmarshal([]byte(s), &expected); err != nil {
+			return false
+		}
+	}
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return false
+	}
+	return equalJSON(expected, actual, strict)
+}
+
+func equalJSON(expected, actual interface{}, strict bool) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok || (strict && len(a) != len(e)) {
+			return false
+		}
+		for k, v := range e {
+			av, ok := a[k]
+			if !ok || !equalJSON(v, av, strict) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		if strict {
+			for i := range e {
+				if !equalJSON(e[i], a[i], strict) {
+					return false
+				}
+			}
+			return true
+		}
+		used := make([]bool, len(a))
+		for _, v := range e {
+			found := false
+			for i, av := range a {
+				if !used[i] && equalJSON(v, av, strict) {
+					used[i], found = true, true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return expected == actual
+	}
+}
@@ -0,0 +1,166 @@
+package inmemory
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+func TestMatchRequest(t *testing.T) {
+	rq := httptest.NewRequest(http.MethodPost, "/pets/12?tag=a&tag=b&limit=10", strings.NewReader(`{"name":"Rex","age":2,"tags":["a","b"]}`))
+	rq.Header.Set("Content-Type", "application/json")
+	rq.Header.Add("X-Id", "1")
+	rq.Header.Add("X-Id", "2")
+	rq.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+	recorded := newRecordedRequest(rq, []byte(`{"name":"Rex","age":2,"tags":["a","b"]}`))
+
+	for name, tc := range map[string]struct {
+		matcher client.HTTPRequest
+		matched bool
+	}{
+		"empty matcher":          {matcher: client.HTTPRequest{}, matched: true},
+		"method":                 {matcher: client.HTTPRequest{Method: http.MethodPost}, matched: true},
+		"method regexp":          {matcher: client.HTTPRequest{Method: "P(UT|OST)"}, matched: true},
+		"negated method":         {matcher: client.HTTPRequest{Method: "!POST"}, matched: false},
+		"path":                   {matcher: client.HTTPRequest{Path: "/pets/12"}, matched: true},
+		"path regexp":            {matcher: client.HTTPRequest{Path: "/pets/[0-9]+"}, matched: true},
+		"path is fully matched":  {matcher: client.HTTPRequest{Path: "/pets"}, matched: false},
+		"negated path":           {matcher: client.HTTPRequest{Path: "!/dogs/.*"}, matched: true},
+		"lookahead is invalid":   {matcher: client.HTTPRequest{Path: "/pets(?!/1$)/.*"}, matched: false},
+		"path parameter":         {matcher: client.HTTPRequest{Path: "/pets/{id}", PathParameters: map[string][]string{"id": {"[0-9]+"}}}, matched: true},
+		"path parameter differs": {matcher: client.HTTPRequest{Path: "/pets/{id}", PathParameters: map[string][]string{"id": {"1"}}}, matched: false},
+		"query subset": {
+			matcher: client.HTTPRequest{QueryStringParameters: map[string]interface{}{"tag": []string{"b"}}},
+			matched: true,
+		},
+		"query matching key": {
+			matcher: client.HTTPRequest{QueryStringParameters: map[string]interface{}{
+				"tag": []string{"b"}, client.KeyMatchStyleKey: string(client.MatchingKey),
+			}},
+			matched: false,
+		},
+		"missing query": {
+			matcher: client.HTTPRequest{QueryStringParameters: map[string]interface{}{"offset": []string{".*"}}},
+			matched: false,
+		},
+		"optional query is not sent": {
+			matcher: client.HTTPRequest{QueryStringParameters: map[string]interface{}{"?offset": []string{"0"}}},
+			matched: true,
+		},
+		"optional query differs": {
+			matcher: client.HTTPRequest{QueryStringParameters: map[string]interface{}{"?limit": []string{"20"}}},
+			matched: false,
+		},
+		"negated query key": {
+			matcher: client.HTTPRequest{QueryStringParameters: map[string]interface{}{"!limit": []string{".*"}}},
+			matched: false,
+		},
+		"negated query value": {
+			matcher: client.HTTPRequest{QueryStringParameters: map[string]interface{}{"limit": []string{"!20"}}},
+			matched: true,
+		},
+		"header values": {
+			matcher: client.HTTPRequest{Headers: map[string]interface{}{"x-id": []string{"1", "2"}}},
+			matched: true,
+		},
+		"header value differs": {
+			matcher: client.HTTPRequest{Headers: map[string]interface{}{"X-Id": []string{"3"}}},
+			matched: false,
+		},
+		"cookie":                {matcher: client.HTTPRequest{Cookies: map[string]string{"session": "s[0-9]"}}, matched: true},
+		"cookie differs":        {matcher: client.HTTPRequest{Cookies: map[string]string{"session": "s2"}}, matched: false},
+		"json body":             {matcher: client.HTTPRequest{Body: map[string]interface{}{"name": "Rex"}}, matched: true},
+		"string body":           {matcher: client.HTTPRequest{Body: `{"name":"Rex"}`}, matched: false},
+		"only matching fields":  {matcher: client.HTTPRequest{Body: client.Body{Type: client.JSONBody, JSON: map[string]interface{}{"tags": []string{"b", "a"}}}}, matched: true},
+		"strict json":           {matcher: client.HTTPRequest{Body: client.Body{Type: client.JSONBody, JSON: map[string]interface{}{"name": "Rex"}, MatchType: client.Strict}}, matched: false},
+		"json path":             {matcher: client.HTTPRequest{Body: client.Body{Type: client.JSONPathBody, JSONPath: "$[?(@.age < 3)]"}}, matched: true},
+		"json schema":           {matcher: client.HTTPRequest{Body: client.Body{Type: client.JSONSchemaBody, JSONSchema: `{"required": ["owner"]}`}}, matched: false},
+		"substring body":        {matcher: client.HTTPRequest{Body: client.Body{Type: client.StringBody, String: `"Rex"`, SubString: true}}, matched: true},
+		"regexp body":           {matcher: client.HTTPRequest{Body: client.Body{Type: client.RegexBody, Regex: `.*"age":[0-9].*`}}, matched: true},
+		"negated body":          {matcher: client.HTTPRequest{Body: client.Body{Type: client.RegexBody, Regex: ".*Rex.*", Not: true}}, matched: false},
+		"not request":           {matcher: client.HTTPRequest{Method: http.MethodGet, Not: true}, matched: true},
+		"not request of fields": {matcher: client.HTTPRequest{Method: http.MethodPost, Path: "/pets/12", Not: true}, matched: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// body matchers are received as JSON objects on the wire
+			matcher := client.HTTPRequest{}
+			require.NoError(t, normalize(tc.matcher, &matcher))
+			assert.Equal(t, tc.matched, matchRequest(&matcher, recorded))
+		})
+	}
+}
+
+func TestMismatchReasons(t *testing.T) {
+	recorded := newRecordedRequest(httptest.NewRequest(http.MethodGet, "/pets", nil), nil)
+	reasons := mismatchReasons(&client.HTTPRequest{Method: http.MethodGet, Path: "/dogs"}, recorded)
+	assert.Equal(t, []string{
+		"method matched",
+		"path didn't match",
+		"queryParameters matched",
+		"headers matched",
+		"cookies matched",
+		"body matched",
+	}, reasons)
+}
+
+func TestPathParameters(t *testing.T) {
+	params, ok := PathParameters("/pets/{id}/toys/{toy}", "/pets/1/toys/ball")
+	require.True(t, ok)
+	assert.Equal(t, map[string][]string{"id": {"1"}, "toy": {"ball"}}, params)
+
+	_, ok = PathParameters("/pets/{id}", "/pets/1/toys")
+	assert.False(t, ok)
+}
+
+func TestMatchRecorded(t *testing.T) {
+	// the request in the form mock server app records it
+	recorded := client.HTTPRequest{
+		Method:                http.MethodPost,
+		Path:                  "/pets",
+		QueryStringParameters: map[string]interface{}{"tag": []interface{}{"a", "b"}},
+		Headers:               map[string]interface{}{"x-id": []interface{}{"1"}},
+		Cookies:               map[string]string{"session": "s1"},
+		Body:                  map[string]interface{}{"type": "JSON", "json": `{"name": "Rex"}`},
+	}
+	for name, tc := range map[string]struct {
+		matcher client.HTTPRequest
+		matched bool
+		reasons []string
+	}{
+		"matched": {
+			matcher: client.HTTPRequest{
+				Method:                http.MethodPost,
+				QueryStringParameters: map[string]interface{}{"tag": []string{"b"}},
+				Headers:               map[string]interface{}{"X-Id": []string{"1"}},
+				Cookies:               map[string]string{"session": "s1"},
+				Body:                  client.Body{Type: client.JSONBody, JSON: map[string]interface{}{"name": "Rex"}},
+			},
+			matched: true,
+		},
+		"not matched": {
+			matcher: client.HTTPRequest{Path: "/dogs", Cookies: map[string]string{"session": "s2"}},
+			reasons: []string{"path didn't match", "cookies didn't match"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			matched, reasons := MatchRecorded(tc.matcher, recorded)
+			assert.Equal(t, tc.matched, matched)
+			var mismatched []string
+			for _, reason := range reasons {
+				if strings.HasSuffix(reason, "didn't match") {
+					mismatched = append(mismatched, reason)
+				}
+			}
+			assert.Equal(t, tc.reasons, mismatched)
+		})
+	}
+
+	assert.False(t, Supported(client.HTTPRequest{Body: client.Body{Type: client.XPathBody, XPath: "/pet"}}))
+	assert.True(t, Supported(client.HTTPRequest{Body: client.Body{Type: client.RegexBody, Regex: ".*"}}))
+}
@@ -0,0 +1,76 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/openapi"
+)
+
+// OpenAPIExpectation creates expectations with example responses for operations of OpenAPI document.
+func (s *Server) OpenAPIExpectation(ctx context.Context, request client.OpenAPIExpectation) ([]client.Expectation, error) {
+	doc, err := openapi.Load(request.SpecURLOrPayload)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to setup openapi expectations")
+	}
+
+	operations := doc.Operations()
+	statuses := make([]int, len(operations))
+	if len(request.OperationsAndResponses) > 0 {
+		operations = operations[:0:0]
+		statuses = statuses[:0]
+		ids := make([]string, 0, len(request.OperationsAndResponses))
+		for id := range request.OperationsAndResponses {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			o, ok := doc.Operation(id)
+			if !ok {
+				return nil, errors.Errorf("unable to setup openapi expectations: operation %s is not found", id)
+			}
+			code := 0
+			if status := request.OperationsAndResponses[id]; status != "" {
+				if code, err = strconv.Atoi(status); err != nil {
+					return nil, errors.Errorf("unable to setup openapi expectations: invalid status %s of operation %s", status, id)
+				}
+			}
+			operations = append(operations, o)
+			statuses = append(statuses, code)
+		}
+	}
+
+	out := make([]client.Expectation, 0, len(operations))
+	for i, o := range operations {
+		rs, err := o.ExampleResponse(statuses[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to setup openapi expectations")
+		}
+		e := client.Expectation{
+			ID:          uuid.NewString(),
+			HTTPRequest: &client.HTTPRequest{Method: o.Method, Path: o.Path},
+			HTTPResponse: &client.HTTPResponse{
+				StatusCode: rs.StatusCode,
+				Body:       rs.Body,
+			},
+			Times:      &client.Times{Unlimited: true},
+			TimeToLive: &client.TimeToLive{Unlimited: true},
+		}
+		if rs.ContentType != "" {
+			e.HTTPResponse.Headers = map[string]interface{}{"Content-Type": []string{rs.ContentType}}
+		}
+		out = append(out, e)
+	}
+
+	for _, e := range out {
+		if err := s.Expectation(ctx, e); err != nil {
+			return nil, errors.Wrap(err, "unable to setup openapi expectations")
+		}
+	}
+	return out, nil
+}
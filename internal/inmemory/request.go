@@ -0,0 +1,152 @@
+package inmemory
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+type recordedRequest struct {
+	method  string
+	path    string
+	query   url.Values
+	headers http.Header
+	body    []byte
+}
+
+func newRecordedRequest(r *http.Request, body []byte) *recordedRequest {
+	headers := r.Header.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	if r.Host != "" {
+		headers.Set("Host", r.Host)
+	}
+	return &recordedRequest{
+		method:  r.Method,
+		path:    r.URL.Path,
+		query:   r.URL.Query(),
+		headers: headers,
+		body:    body,
+	}
+}
+
+// fromClient restores the request recorded by mock server app, see toClient.
+func fromClient(rq client.HTTPRequest) *recordedRequest {
+	body, contentType := client.BodyBytes(rq.Body)
+	r := &recordedRequest{
+		method:  rq.Method,
+		path:    rq.Path,
+		query:   url.Values{},
+		headers: http.Header{},
+		body:    body,
+	}
+	for k, v := range rq.QueryStringParameters {
+		r.query[k] = client.Values(v)
+	}
+	for k, v := range rq.Headers {
+		for _, value := range client.Values(v) {
+			r.headers.Add(k, value)
+		}
+	}
+	if contentType != "" && r.headers.Get("Content-Type") == "" {
+		r.headers.Set("Content-Type", contentType)
+	}
+	if len(rq.Cookies) > 0 && r.headers.Get("Cookie") == "" {
+		names := make([]string, 0, len(rq.Cookies))
+		for name := range rq.Cookies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r.headers.Add("Cookie", (&http.Cookie{Name: name, Value: rq.Cookies[name]}).String())
+		}
+	}
+	return r
+}
+
+func (r *recordedRequest) contentType() string {
+	return r.headers.Get("Content-Type")
+}
+
+// cookies returns cookies sent within Cookie header, the first value is taken for repeated name.
+func (r *recordedRequest) cookies() map[string]string {
+	cookies := map[string]string{}
+	for _, c := range (&http.Request{Header: r.headers}).Cookies() {
+		if _, ok := cookies[c.Name]; !ok {
+			cookies[c.Name] = c.Value
+		}
+	}
+	return cookies
+}
+
+// toClient represents recorded request in the same way as mock server app returns it on retrieve.
+func (r *recordedRequest) toClient() client.HTTPRequest {
+	rq := client.HTTPRequest{
+		Method: r.method,
+		Path:   r.path,
+		Body:   recordedBody(r.body, r.contentType()),
+	}
+	if len(r.query) > 0 {
+		rq.QueryStringParameters = map[string]interface{}{}
+		for k, v := range r.query {
+			rq.QueryStringParameters[k] = v
+		}
+	}
+	if len(r.headers) > 0 {
+		rq.Headers = map[string]interface{}{}
+		for k, v := range r.headers {
+			rq.Headers[k] = v
+		}
+	}
+	if cookies := r.cookies(); len(cookies) > 0 {
+		rq.Cookies = cookies
+	}
+	return rq
+}
+
+func recordedBody(body []byte, contentType string) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case isJSON(mediaType) && json.Valid(body):
+		return map[string]interface{}{
+			"type":        "JSON",
+			"json":        json.RawMessage(body),
+			"rawBytes":    base64.StdEncoding.EncodeToString(body),
+			"contentType": contentType,
+		}
+	case !utf8.Valid(body):
+		return map[string]interface{}{
+			"type":        "BINARY",
+			"base64Bytes": base64.StdEncoding.EncodeToString(body),
+			"contentType": contentType,
+		}
+	case contentType == "":
+		return string(body)
+	default:
+		return map[string]interface{}{
+			"type":        "STRING",
+			"string":      string(body),
+			"contentType": contentType,
+		}
+	}
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
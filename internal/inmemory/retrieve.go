@@ -0,0 +1,211 @@
+package inmemory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+type recordedResponse struct {
+	status  int
+	headers http.Header
+	body    []byte
+}
+
+// toClient represents recorded response in the same way as mock server app returns it on retrieve.
+func (r *recordedResponse) toClient() *client.HTTPResponse {
+	rs := &client.HTTPResponse{
+		StatusCode: r.status,
+		Body:       recordedBody(r.body, r.headers.Get("Content-Type")),
+	}
+	if len(r.headers) > 0 {
+		rs.Headers = map[string]interface{}{}
+		for k, v := range r.headers {
+			rs.Headers[k] = v
+		}
+	}
+	for _, c := range (&http.Response{Header: r.headers}).Cookies() {
+		if rs.Cookies == nil {
+			rs.Cookies = map[string]string{}
+		}
+		rs.Cookies[c.Name] = c.Value
+	}
+	return rs
+}
+
+// responseRecorder copies the response written to the system under test, hijacked connection is not recorded.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	status   int
+	body     bytes.Buffer
+	hijacked bool
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connection can not be hijacked")
+	}
+	r.hijacked = true
+	return hijacker.Hijack()
+}
+
+// response returns the recorded response, it's nil if nothing was written.
+func (r *responseRecorder) response() *recordedResponse {
+	if r.hijacked || r.status == 0 {
+		return nil
+	}
+	return &recordedResponse{
+		status:  r.status,
+		headers: r.Header().Clone(),
+		body:    r.body.Bytes(),
+	}
+}
+
+func (s *Server) recordResponse(entry *logEntry, r *responseRecorder) {
+	rs := r.response()
+	if rs == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.response = rs
+	s.logf("returning response:\n\n  %s\n\n for request:\n\n  %s",
+		toJSON(entry.response.toClient()), toJSON(entry.request.toClient()))
+}
+
+func (s *Server) RetrieveRequestResponses(_ context.Context, request client.RetrieveRequest) ([]client.HTTPRequestAndResponse, error) {
+	matcher, err := retrieveMatcher(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve requests and responses")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := []client.HTTPRequestAndResponse{}
+	for _, entry := range s.log {
+		if entry.response == nil || !matchRequest(matcher, entry.request) {
+			continue
+		}
+		rq := entry.request.toClient()
+		out = append(out, client.HTTPRequestAndResponse{
+			HTTPRequest:  &rq,
+			HTTPResponse: entry.response.toClient(),
+			Timestamp:    entry.timestamp.Format(timestampLayout),
+		})
+	}
+	var rs []client.HTTPRequestAndResponse
+	err = normalize(out, &rs)
+	return rs, errors.Wrap(err, "unable to retrieve requests and responses")
+}
+
+func (s *Server) RetrieveActiveExpectations(_ context.Context, request client.RetrieveRequest) ([]client.Expectation, error) {
+	matcher, err := retrieveMatcher(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve active expectations")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+	out := []client.Expectation{}
+	for _, e := range s.expectations {
+		if isBlank(matcher) || toJSON(e.HTTPRequest) == toJSON(matcher) {
+			out = append(out, e.Expectation)
+		}
+	}
+	var rs []client.Expectation
+	err = normalize(out, &rs)
+	return rs, errors.Wrap(err, "unable to retrieve active expectations")
+}
+
+func (s *Server) RetrieveRecordedExpectations(_ context.Context, request client.RetrieveRequest) ([]client.Expectation, error) {
+	matcher, err := retrieveMatcher(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve recorded expectations")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := []client.Expectation{}
+	for _, e := range s.recorded {
+		if matchRequest(matcher, e.request) {
+			out = append(out, e.Expectation)
+		}
+	}
+	var rs []client.Expectation
+	err = normalize(out, &rs)
+	return rs, errors.Wrap(err, "unable to retrieve recorded expectations")
+}
+
+func (s *Server) RetrieveLogMessages(_ context.Context, request client.RetrieveRequest) ([]string, error) {
+	matcher, err := retrieveMatcher(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve log messages")
+	}
+	if !isBlank(matcher) {
+		return nil, errors.New("unable to retrieve log messages: filtering of log messages is not supported by in-memory mock server")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.messages...), nil
+}
+
+const timestampLayout = "2006-01-02 15:04:05.000"
+
+// logf adds log message in the same format as mock server app does, it has to be called under the lock.
+func (s *Server) logf(format string, args ...interface{}) {
+	message := time.Now().Format(timestampLayout) + " - " + fmt.Sprintf(format, args...)
+	s.messages = append(s.messages, strings.TrimSpace(message))
+}
+
+func retrieveMatcher(request client.RetrieveRequest) (*client.HTTPRequest, error) {
+	matcher := client.HTTPRequest{}
+	if err := normalize(request, &matcher); err != nil {
+		return nil, err
+	}
+	if err := unsupported(&matcher); err != nil {
+		return nil, err
+	}
+	return &matcher, nil
+}
+
+func isBlank(m *client.HTTPRequest) bool {
+	return m == nil || toJSON(m) == toJSON(client.HTTPRequest{})
+}
@@ -0,0 +1,523 @@
+package inmemory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+var (
+	_ client.Client = (*Server)(nil)
+	_ http.Handler  = (*Server)(nil)
+)
+
+// Server is in-process replacement of mock server app.
+// It implements client.Client for control plane calls and http.Handler to serve mocked endpoints.
+type Server struct {
+	mu sync.Mutex
+
+	expectations []*expectation
+	log          []*logEntry
+	messages     []string
+	remote       *url.URL
+	recorded     []recordedExpectation
+	sequence     int
+	callbacks    map[string]client.CallbackHandler
+}
+
+type expectation struct {
+	client.Expectation
+
+	sequence int
+	created  time.Time
+}
+
+type recordedExpectation struct {
+	client.Expectation
+
+	request *recordedRequest
+}
+
+type logEntry struct {
+	request       *recordedRequest
+	response      *recordedResponse
+	expectationID string
+	timestamp     time.Time
+}
+
+func NewServer() *Server {
+	return &Server{}
+}
+
+// NewProxy creates Server which proxies requests matched by no expectation to the remote and records
+// the exchanges as expectations the same way as mock server app does in proxy mode.
+func NewProxy(remote *url.URL) *Server {
+	return &Server{remote: remote}
+}
+
+func (s *Server) Expectation(_ context.Context, request client.Expectation) error {
+	e := client.Expectation{}
+	if err := normalize(request, &e); err != nil {
+		return errors.Wrap(err, "unable to setup expectation")
+	}
+	if e.HTTPResponse == nil && e.HTTPResponseTemplate == nil && e.HTTPResponseObjectCallback == nil &&
+		e.HTTPError == nil && e.HTTPForward == nil && e.HTTPOverrideForwardedRequest == nil {
+		return errors.Errorf("unable to setup expectation %s: no action is defined", e.ID)
+	}
+	if err := unsupported(e.HTTPRequest); err != nil {
+		return errors.Wrapf(err, "unable to setup expectation %s", e.ID)
+	}
+	if e.HTTPResponseTemplate != nil {
+		if err := validateTemplate(e.HTTPResponseTemplate); err != nil {
+			return errors.Wrapf(err, "unable to setup expectation %s", e.ID)
+		}
+	}
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logf("creating expectation:\n\n  %s", toJSON(e))
+	s.removeExpectation(e.ID)
+	s.sequence++
+	s.expectations = append(s.expectations, &expectation{
+		Expectation: e,
+		sequence:    s.sequence,
+		created:     time.Now(),
+	})
+	return nil
+}
+
+func (s *Server) Verify(_ context.Context, verify client.Verify) error {
+	request := client.Verify{}
+	if err := normalize(verify, &request); err != nil {
+		return errors.Wrap(err, "unable to verify expectation")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expectationID, matcher := "", request.HTTPRequest
+	if request.ExpectationID != nil {
+		expectationID, matcher = request.ExpectationID.ID, nil
+	}
+	if err := unsupported(matcher); err != nil {
+		return errors.Wrap(err, "unable to verify expectation")
+	}
+	s.logf("verifying requests that match:\n\n  %s", toJSON(request))
+
+	count := 0
+	for _, entry := range s.log {
+		if s.verifies(expectationID, matcher, entry) {
+			count++
+		}
+	}
+
+	times := client.VerificationTimes{AtLeast: 1, AtMost: -1}
+	if request.Times != nil {
+		times = *request.Times
+	}
+	if count >= times.AtLeast && (times.AtMost < 0 || count <= times.AtMost) {
+		return nil
+	}
+
+	return client.VerificationError(fmt.Sprintf("Request not found %s, expected:<%s> but was:<%s>",
+		describeTimes(times), toJSON(expected(expectationID, matcher)), toJSON(s.recordedRequests(nil))))
+}
+
+func (s *Server) VerifySequence(_ context.Context, verify client.VerifySequence) error {
+	request := client.VerifySequence{}
+	if err := normalize(verify, &request); err != nil {
+		return errors.Wrap(err, "unable to verify sequence expectations")
+	}
+	for i := range request.HTTPRequests {
+		if err := unsupported(&request.HTTPRequests[i]); err != nil {
+			return errors.Wrap(err, "unable to verify sequence expectations")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	steps := len(request.ExpectationIDs)
+	step := func(i int) (string, *client.HTTPRequest) {
+		return request.ExpectationIDs[i].ID, nil
+	}
+	var sequence interface{} = request.ExpectationIDs
+	if steps == 0 {
+		steps = len(request.HTTPRequests)
+		step = func(i int) (string, *client.HTTPRequest) {
+			return "", &request.HTTPRequests[i]
+		}
+		sequence = request.HTTPRequests
+	}
+
+	next := 0
+	for _, entry := range s.log {
+		if next == steps {
+			break
+		}
+		if id, matcher := step(next); s.verifies(id, matcher, entry) {
+			next++
+		}
+	}
+	if next == steps {
+		return nil
+	}
+
+	return client.VerificationError(fmt.Sprintf("Request sequence not found, expected:<%s> but was:<%s>",
+		toJSON(sequence), toJSON(s.recordedRequests(nil))))
+}
+
+func (s *Server) Clear(_ context.Context, request client.ClearRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logf("clearing expectation and request logs that match:\n\n  %s", toJSON(request.ExpectationID))
+	s.removeExpectation(request.ExpectationID.ID)
+
+	log := s.log[:0]
+	for _, entry := range s.log {
+		if entry.expectationID != request.ExpectationID.ID {
+			log = append(log, entry)
+		}
+	}
+	s.log = log
+	return nil
+}
+
+func (s *Server) Reset(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expectations = nil
+	s.log = nil
+	s.messages = nil
+	s.recorded = nil
+	s.callbacks = nil
+	s.logf("resetting all expectations and request logs")
+	return nil
+}
+
+func (s *Server) Retrieve(_ context.Context, request client.RetrieveRequest) (client.RetrieveResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matcher := client.HTTPRequest{}
+	if err := normalize(request, &matcher); err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve recorded requests")
+	}
+	if err := unsupported(&matcher); err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve recorded requests")
+	}
+	rs := client.RetrieveResponse{}
+	err := normalize(s.recordedRequests(&matcher), &rs)
+	return rs, errors.Wrap(err, "unable to retrieve recorded requests")
+}
+
+// Callback registers the handler which is called directly for requests matched by object callback expectations.
+func (s *Server) Callback(_ context.Context, handler client.CallbackHandler) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.callbacks == nil {
+		s.callbacks = map[string]client.CallbackHandler{}
+	}
+	id := uuid.NewString()
+	s.callbacks[id] = handler
+	return id, nil
+}
+
+// CloseCallback removes the handler registered by Callback.
+func (s *Server) CloseCallback(clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.callbacks, clientID)
+}
+
+// ServeHTTP handles requests sent by the system under test to mocked endpoints.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rq := newRecordedRequest(r, body)
+	e, entry := s.match(rq)
+	recorder := newResponseRecorder(w)
+	defer s.recordResponse(entry, recorder)
+
+	w = recorder
+	switch {
+	case e == nil && s.remote != nil:
+		s.writeProxy(recorder, r, rq)
+	case e == nil:
+		w.WriteHeader(http.StatusNotFound)
+	case e.HTTPError != nil:
+		writeError(w, r, e.HTTPError)
+	case e.HTTPResponse != nil:
+		writeResponse(w, r, e.HTTPResponse)
+	case e.HTTPResponseTemplate != nil:
+		writeTemplate(w, r, rq, e.HTTPRequest, e.HTTPResponseTemplate)
+	case e.HTTPResponseObjectCallback != nil:
+		s.writeCallback(w, r, rq, e.HTTPResponseObjectCallback)
+	case e.HTTPForward != nil:
+		writeForward(w, r, rq, e.HTTPForward)
+	case e.HTTPOverrideForwardedRequest != nil:
+		writeOverrideForward(w, r, rq, e.HTTPOverrideForwardedRequest)
+	}
+}
+
+// match finds the active expectation with the highest priority, updates its remaining times and logs the request.
+func (s *Server) match(rq *recordedRequest) (*client.Expectation, *logEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+	active := make([]*expectation, len(s.expectations))
+	copy(active, s.expectations)
+	sort.SliceStable(active, func(i, j int) bool {
+		if active[i].Priority != active[j].Priority {
+			return active[i].Priority > active[j].Priority
+		}
+		return active[i].sequence < active[j].sequence
+	})
+
+	entry := &logEntry{request: rq, timestamp: time.Now()}
+	s.log = append(s.log, entry)
+	s.logf("received request:\n\n  %s", toJSON(rq.toClient()))
+
+	for _, e := range active {
+		if !matchRequest(e.HTTPRequest, rq) {
+			s.logf("request:\n\n  %s\n\n didn't match expectation:\n\n  %s\n\n because:\n\n  %s",
+				toJSON(rq.toClient()), toJSON(e.Expectation), strings.Join(mismatchReasons(e.HTTPRequest, rq), "\n  "))
+			continue
+		}
+		entry.expectationID = e.ID
+		s.logf("request:\n\n  %s\n\n matched expectation:\n\n  %s", toJSON(rq.toClient()), toJSON(e.Expectation))
+		matched := e.Expectation
+		if e.Times != nil && !e.Times.Unlimited {
+			e.Times.RemainingTimes--
+			if e.Times.RemainingTimes <= 0 {
+				s.removeExpectation(e.ID)
+			}
+		}
+		return &matched, entry
+	}
+	s.logf("no expectation for:\n\n  %s", toJSON(rq.toClient()))
+	return nil, entry
+}
+
+func (s *Server) verifies(expectationID string, matcher *client.HTTPRequest, entry *logEntry) bool {
+	if expectationID != "" {
+		return entry.expectationID == expectationID
+	}
+	return matchRequest(matcher, entry.request)
+}
+
+func (s *Server) recordedRequests(matcher *client.HTTPRequest) []client.HTTPRequest {
+	out := []client.HTTPRequest{}
+	for _, entry := range s.log {
+		if matchRequest(matcher, entry.request) {
+			out = append(out, entry.request.toClient())
+		}
+	}
+	return out
+}
+
+func (s *Server) removeExpectation(id string) {
+	expectations := s.expectations[:0]
+	for _, e := range s.expectations {
+		if e.ID != id {
+			expectations = append(expectations, e)
+		}
+	}
+	s.expectations = expectations
+}
+
+func (s *Server) removeExpired() {
+	now := time.Now()
+	expectations := s.expectations[:0]
+	for _, e := range s.expectations {
+		ttl := e.TimeToLive
+		if ttl != nil && !ttl.Unlimited && now.After(e.created.Add(duration(ttl.TimeUnit, ttl.TimeToLive))) {
+			continue
+		}
+		expectations = append(expectations, e)
+	}
+	s.expectations = expectations
+}
+
+func (s *Server) writeCallback(w http.ResponseWriter, r *http.Request, rq *recordedRequest, c *client.HTTPObjectCallback) {
+	s.mu.Lock()
+	handler, ok := s.callbacks[c.ClientID]
+	s.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !wait(r.Context(), c.Delay) {
+		return
+	}
+
+	callbackRequest := client.HTTPRequest{}
+	if err := normalize(rq.toClient(), &callbackRequest); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	rs := client.HTTPResponse{}
+	if err := normalize(handler.Handle(callbackRequest), &rs); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeResponse(w, r, &rs)
+}
+
+// unsupported returns error for request matchers which in-memory mock server is not able to evaluate.
+func unsupported(m *client.HTTPRequest) error {
+	if m == nil {
+		return nil
+	}
+	if body, ok := m.Body.(map[string]interface{}); ok && body["type"] == "XPATH" {
+		return errors.New("XPATH body matcher is not supported by in-memory mock server")
+	}
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, rs *client.HTTPResponse) {
+	if !wait(r.Context(), rs.Delay) {
+		return
+	}
+
+	body, contentType := client.BodyBytes(rs.Body)
+	for k, v := range rs.Headers {
+		for _, value := range client.Values(v) {
+			w.Header().Add(k, value)
+		}
+	}
+	for name, value := range rs.Cookies {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: value})
+	}
+	if contentType != "" && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	status := rs.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, rs *client.HTTPError) {
+	if !wait(r.Context(), rs.Delay) {
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if rs.ResponseBytes != "" {
+		data, err := base64.StdEncoding.DecodeString(rs.ResponseBytes)
+		if err != nil {
+			data = []byte(rs.ResponseBytes)
+		}
+		_, _ = buf.Write(data)
+		_ = buf.Flush()
+	}
+}
+
+// wait blocks for the delay, it returns false if the request was cancelled before the delay passed.
+func wait(ctx context.Context, d *client.Delay) bool {
+	if d == nil {
+		return true
+	}
+	timer := time.NewTimer(duration(d.TimeUnit, d.Value))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func duration(unit client.TimeUnit, value int) time.Duration {
+	d := time.Duration(value)
+	switch unit {
+	case client.DAYS:
+		return d * 24 * time.Hour
+	case client.HOURS:
+		return d * time.Hour
+	case client.MINUTES:
+		return d * time.Minute
+	case client.SECONDS:
+		return d * time.Second
+	case client.MICROSECONDS:
+		return d * time.Microsecond
+	case client.NANOSECONDS:
+		return d
+	default:
+		return d * time.Millisecond
+	}
+}
+
+func describeTimes(t client.VerificationTimes) string {
+	switch {
+	case t.AtLeast == t.AtMost:
+		return fmt.Sprintf("exactly %d times", t.AtLeast)
+	case t.AtMost < 0:
+		return fmt.Sprintf("at least %d times", t.AtLeast)
+	default:
+		return fmt.Sprintf("at least %d times and at most %d times", t.AtLeast, t.AtMost)
+	}
+}
+
+func expected(expectationID string, matcher *client.HTTPRequest) interface{} {
+	if expectationID != "" {
+		return client.ExpectationID{ID: expectationID}
+	}
+	return matcher
+}
+
+// normalize converts in to out through JSON the same way as it's done on the wire to mock server app.
+func normalize(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,236 @@
+package inmemory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+func serve(s *Server, method, path string) int {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+func respond(id, path string, status int) client.Expectation {
+	return client.Expectation{
+		ID:           id,
+		HTTPRequest:  &client.HTTPRequest{Method: http.MethodGet, Path: path},
+		HTTPResponse: &client.HTTPResponse{StatusCode: status},
+	}
+}
+
+func TestServerTimes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		times    *client.Times
+		statuses []int
+	}{
+		"no times":        {statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		"unlimited":       {times: &client.Times{Unlimited: true}, statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		"remaining times": {times: &client.Times{RemainingTimes: 2}, statuses: []int{http.StatusOK, http.StatusOK, http.StatusNotFound}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer()
+			e := respond("pets", "/pets", http.StatusOK)
+			e.Times = tc.times
+			require.NoError(t, s.Expectation(context.Background(), e))
+
+			var statuses []int
+			for range tc.statuses {
+				statuses = append(statuses, serve(s, http.MethodGet, "/pets"))
+			}
+			assert.Equal(t, tc.statuses, statuses)
+		})
+	}
+}
+
+func TestServerTimeToLive(t *testing.T) {
+	for name, tc := range map[string]struct {
+		ttl    *client.TimeToLive
+		status int
+	}{
+		"unlimited": {ttl: &client.TimeToLive{Unlimited: true}, status: http.StatusOK},
+		"alive":     {ttl: &client.TimeToLive{TimeUnit: client.HOURS, TimeToLive: 2}, status: http.StatusOK},
+		"expired":   {ttl: &client.TimeToLive{TimeUnit: client.MINUTES, TimeToLive: 30}, status: http.StatusNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer()
+			e := respond("pets", "/pets", http.StatusOK)
+			e.TimeToLive = tc.ttl
+			require.NoError(t, s.Expectation(context.Background(), e))
+			s.expectations[0].created = time.Now().Add(-time.Hour)
+
+			assert.Equal(t, tc.status, serve(s, http.MethodGet, "/pets"))
+		})
+	}
+}
+
+func TestServerPriority(t *testing.T) {
+	for name, tc := range map[string]struct {
+		expectations []client.Expectation
+		status       int
+	}{
+		"first created wins": {
+			expectations: []client.Expectation{respond("a", "/pets", http.StatusOK), respond("b", "/pets", http.StatusAccepted)},
+			status:       http.StatusOK,
+		},
+		"higher priority wins": {
+			expectations: []client.Expectation{
+				respond("a", "/pets", http.StatusOK),
+				{ID: "b", Priority: 10, HTTPRequest: &client.HTTPRequest{Path: "/p.*"}, HTTPResponse: &client.HTTPResponse{StatusCode: http.StatusAccepted}},
+			},
+			status: http.StatusAccepted,
+		},
+		"negative priority": {
+			expectations: []client.Expectation{
+				{ID: "a", Priority: -1, HTTPRequest: &client.HTTPRequest{Path: "/pets"}, HTTPResponse: &client.HTTPResponse{StatusCode: http.StatusOK}},
+				respond("b", "/pets", http.StatusAccepted),
+			},
+			status: http.StatusAccepted,
+		},
+		"the same id replaces expectation": {
+			expectations: []client.Expectation{respond("a", "/pets", http.StatusOK), respond("a", "/pets", http.StatusAccepted)},
+			status:       http.StatusAccepted,
+		},
+		"not matched": {
+			expectations: []client.Expectation{respond("a", "/dogs", http.StatusOK)},
+			status:       http.StatusNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer()
+			for _, e := range tc.expectations {
+				require.NoError(t, s.Expectation(context.Background(), e))
+			}
+			assert.Equal(t, tc.status, serve(s, http.MethodGet, "/pets"))
+		})
+	}
+}
+
+func TestServerExpectationWithoutAction(t *testing.T) {
+	err := NewServer().Expectation(context.Background(), client.Expectation{ID: "pets"})
+	require.Error(t, err)
+	assert.Equal(t, "unable to setup expectation pets: no action is defined", err.Error())
+}
+
+func TestServerVerify(t *testing.T) {
+	s := NewServer()
+	require.NoError(t, s.Expectation(context.Background(), respond("pets", "/pets", http.StatusOK)))
+	require.NoError(t, s.Expectation(context.Background(), respond("dogs", "/dogs", http.StatusOK)))
+	for _, path := range []string{"/pets", "/dogs", "/pets", "/cats"} {
+		serve(s, http.MethodGet, path)
+	}
+
+	for name, tc := range map[string]struct {
+		verify client.Verify
+		err    string
+	}{
+		"at least once by default": {
+			verify: client.Verify{ExpectationID: &client.ExpectationID{ID: "dogs"}},
+		},
+		"exactly": {
+			verify: client.Verify{ExpectationID: &client.ExpectationID{ID: "pets"}, Times: &client.VerificationTimes{AtLeast: 2, AtMost: 2}},
+		},
+		"at most": {
+			verify: client.Verify{ExpectationID: &client.ExpectationID{ID: "pets"}, Times: &client.VerificationTimes{AtLeast: 0, AtMost: 1}},
+			err:    `Request not found at least 0 times and at most 1 times, expected:<{"id":"pets"}>`,
+		},
+		"request matcher": {
+			verify: client.Verify{HTTPRequest: &client.HTTPRequest{Path: "/cats"}, Times: &client.VerificationTimes{AtLeast: 1, AtMost: 1}},
+		},
+		"never": {
+			verify: client.Verify{HTTPRequest: &client.HTTPRequest{Path: "/.*s"}, Times: &client.VerificationTimes{AtLeast: 0, AtMost: 0}},
+			err:    `Request not found exactly 0 times, expected:<{"method":"","path":"/.*s"}>`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := s.Verify(context.Background(), tc.verify)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			var verificationErr client.VerificationError
+			require.ErrorAs(t, err, &verificationErr)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestServerVerifySequence(t *testing.T) {
+	s := NewServer()
+	require.NoError(t, s.Expectation(context.Background(), respond("pets", "/pets", http.StatusOK)))
+	require.NoError(t, s.Expectation(context.Background(), respond("dogs", "/dogs", http.StatusOK)))
+	for _, path := range []string{"/pets", "/dogs", "/pets"} {
+		serve(s, http.MethodGet, path)
+	}
+
+	for name, tc := range map[string]struct {
+		verify client.VerifySequence
+		found  bool
+	}{
+		"expectation ids": {
+			verify: client.VerifySequence{ExpectationIDs: []client.ExpectationID{{ID: "pets"}, {ID: "dogs"}, {ID: "pets"}}},
+			found:  true,
+		},
+		"gaps are allowed": {
+			verify: client.VerifySequence{ExpectationIDs: []client.ExpectationID{{ID: "pets"}, {ID: "pets"}}},
+			found:  true,
+		},
+		"wrong order": {
+			verify: client.VerifySequence{ExpectationIDs: []client.ExpectationID{{ID: "dogs"}, {ID: "dogs"}}},
+		},
+		"request matchers": {
+			verify: client.VerifySequence{HTTPRequests: []client.HTTPRequest{{Path: "/dogs"}, {Path: "/pets"}}},
+			found:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := s.VerifySequence(context.Background(), tc.verify)
+			if tc.found {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "Request sequence not found")
+		})
+	}
+}
+
+func TestServerClearAndReset(t *testing.T) {
+	s := NewServer()
+	require.NoError(t, s.Expectation(context.Background(), respond("pets", "/pets", http.StatusOK)))
+	require.NoError(t, s.Expectation(context.Background(), respond("dogs", "/dogs", http.StatusOK)))
+	serve(s, http.MethodGet, "/pets")
+	serve(s, http.MethodGet, "/dogs")
+
+	require.NoError(t, s.Clear(context.Background(), client.ClearRequest{ExpectationID: client.ExpectationID{ID: "pets"}}))
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/pets"))
+	active, err := s.RetrieveActiveExpectations(context.Background(), client.RetrieveRequest{})
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "dogs", active[0].ID)
+	recorded, err := s.Retrieve(context.Background(), client.RetrieveRequest{})
+	require.NoError(t, err)
+	// the request matched by cleared expectation is removed from the log, not matched one is kept
+	require.Len(t, recorded, 2)
+	assert.Equal(t, "/dogs", recorded[0].Path)
+	assert.Equal(t, "/pets", recorded[1].Path)
+
+	require.NoError(t, s.Reset(context.Background()))
+	assert.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/dogs"))
+	active, err = s.RetrieveActiveExpectations(context.Background(), client.RetrieveRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	recorded, err = s.Retrieve(context.Background(), client.RetrieveRequest{})
+	require.NoError(t, err)
+	// the request sent after the reset is recorded
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "/dogs", recorded[0].Path)
+}
@@ -0,0 +1,55 @@
+package inmemory
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/mustache"
+)
+
+func validateTemplate(t *client.HTTPResponseTemplate) error {
+	if t.TemplateType != client.MUSTACHE {
+		return errors.Errorf("template type %s is not supported by in-memory mock server", t.TemplateType)
+	}
+	_, err := mustache.Parse(t.Template)
+	return errors.Wrap(err, "invalid mustache template")
+}
+
+func writeTemplate(w http.ResponseWriter, r *http.Request, rq *recordedRequest, matcher *client.HTTPRequest, t *client.HTTPResponseTemplate) {
+	if !wait(r.Context(), t.Delay) {
+		return
+	}
+	rs, err := renderTemplate(t.Template, rq, matcher)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeResponse(w, r, rs)
+}
+
+// renderTemplate renders mustache template into the response, the template has to produce JSON of the response.
+func renderTemplate(template string, rq *recordedRequest, matcher *client.HTTPRequest) (*client.HTTPResponse, error) {
+	t, err := mustache.Parse(template)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mustache template")
+	}
+
+	data := mustache.Request{
+		Method:                rq.method,
+		Path:                  rq.path,
+		QueryStringParameters: rq.query,
+		Headers:               rq.headers,
+		Cookies:               rq.cookies(),
+		Body:                  string(rq.body),
+	}
+	if matcher != nil {
+		data.PathParameters, _ = PathParameters(matcher.Path, rq.path)
+	}
+
+	rs := &client.HTTPResponse{}
+	err = json.Unmarshal([]byte(t.Render(data.Data())), rs)
+	return rs, errors.Wrap(err, "template result is not a valid response")
+}
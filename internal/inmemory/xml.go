@@ -0,0 +1,51 @@
+package inmemory
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// equalXML compares XML documents ignoring formatting, comments and order of attributes.
+func equalXML(expected, actual string) bool {
+	e, err := xmlTokens(expected)
+	if err != nil {
+		return false
+	}
+	a, err := xmlTokens(actual)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(e, a)
+}
+
+func xmlTokens(doc string) ([]string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	var tokens []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			attrs := make([]string, len(t.Attr))
+			for i, a := range t.Attr {
+				attrs[i] = a.Name.Space + ":" + a.Name.Local + "=" + a.Value
+			}
+			sort.Strings(attrs)
+			tokens = append(tokens, "<"+t.Name.Space+":"+t.Name.Local+" "+strings.Join(attrs, " "))
+		case xml.EndElement:
+			tokens = append(tokens, ">")
+		case xml.CharData:
+			if text := strings.TrimSpace(string(t)); text != "" {
+				tokens = append(tokens, text)
+			}
+		}
+	}
+}
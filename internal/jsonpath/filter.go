@@ -0,0 +1,161 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var operators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+type orExpression []andExpression
+
+type andExpression []comparison
+
+type comparison struct {
+	left     operand
+	operator string
+	right    *operand
+}
+
+type operand struct {
+	path    *Path
+	literal interface{}
+}
+
+func parseFilter(expr string) (*orExpression, error) {
+	or := orExpression{}
+	for _, andPart := range splitOutsideQuotes(expr, "||") {
+		and := andExpression{}
+		for _, part := range splitOutsideQuotes(andPart, "&&") {
+			c, err := parseComparison(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, c)
+		}
+		or = append(or, and)
+	}
+	return &or, nil
+}
+
+func parseComparison(expr string) (comparison, error) {
+	for _, op := range operators {
+		parts := splitOutsideQuotes(expr, op)
+		if len(parts) != 2 {
+			continue
+		}
+		left, err := parseOperand(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return comparison{}, err
+		}
+		right, err := parseOperand(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return comparison{}, err
+		}
+		return comparison{left: left, operator: op, right: &right}, nil
+	}
+
+	left, err := parseOperand(expr)
+	if err != nil {
+		return comparison{}, err
+	}
+	if left.path == nil {
+		return comparison{}, errors.Errorf("filter %q has to refer current node by @", expr)
+	}
+	return comparison{left: left}, nil
+}
+
+func parseOperand(s string) (operand, error) {
+	if strings.HasPrefix(s, "@") {
+		steps, err := parseSteps(s[1:])
+		if err != nil {
+			return operand{}, errors.Wrapf(err, "invalid filter path %q", s)
+		}
+		return operand{path: &Path{steps: steps}}, nil
+	}
+	if v, ok := unquote(s); ok {
+		return operand{literal: v}, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return operand{}, errors.Errorf("invalid filter literal %q", s)
+	}
+	return operand{literal: v}, nil
+}
+
+func (or orExpression) eval(node interface{}) bool {
+	for _, and := range or {
+		if and.eval(node) {
+			return true
+		}
+	}
+	return false
+}
+
+func (and andExpression) eval(node interface{}) bool {
+	for _, c := range and {
+		if !c.eval(node) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparison) eval(node interface{}) bool {
+	left, ok := c.left.value(node)
+	if c.right == nil {
+		return ok
+	}
+	right, rightOK := c.right.value(node)
+	if !ok || !rightOK {
+		return false
+	}
+
+	switch c.operator {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+
+	if l, ok := left.(float64); ok {
+		if r, ok := right.(float64); ok {
+			return compare(c.operator, l < r, l == r)
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			return compare(c.operator, l < r, l == r)
+		}
+	}
+	return false
+}
+
+func compare(operator string, less, equal bool) bool {
+	switch operator {
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	default:
+		return false
+	}
+}
+
+func (o operand) value(node interface{}) (interface{}, bool) {
+	if o.path == nil {
+		return o.literal, true
+	}
+	values := o.path.Evaluate(node)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
@@ -0,0 +1,360 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Path is compiled JSONPath expression, it supports child and recursive descent steps, wildcards,
+// indexes, slices, unions and filters with comparison of the current node fields. Filter is applied to items
+// of an array or to an object itself.
+// For example: $.items[0].id, $..id, $.items[?(@.price > 10 && @.category == 'book')].name
+type Path struct {
+	steps []step
+}
+
+type selectorKind int
+
+const (
+	byName selectorKind = iota
+	byWildcard
+	byIndex
+	bySlice
+	byFilter
+)
+
+type step struct {
+	recursive bool
+	kind      selectorKind
+	names     []string
+	indexes   []int
+	slice     [3]*int
+	filter    *orExpression
+}
+
+// Compile parses JSONPath expression.
+func Compile(path string) (*Path, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, errors.Errorf("json path %q has to start with $", path)
+	}
+	steps, err := parseSteps(path[1:])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid json path %q", path)
+	}
+	return &Path{steps: steps}, nil
+}
+
+// Evaluate compiles and evaluates JSONPath expression on decoded JSON document.
+func Evaluate(path string, doc interface{}) ([]interface{}, error) {
+	p, err := Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Evaluate(doc), nil
+}
+
+// EvaluateJSON evaluates JSONPath expression on raw JSON document.
+func EvaluateJSON(path string, data []byte) ([]interface{}, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "invalid json document")
+	}
+	return Evaluate(path, doc)
+}
+
+// Evaluate returns all nodes of decoded JSON document selected by the path.
+func (p *Path) Evaluate(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+	for _, s := range p.steps {
+		if s.recursive {
+			nodes = descendants(nodes)
+		}
+		var next []interface{}
+		for _, n := range nodes {
+			if _, isList := n.([]interface{}); isList && s.recursive && s.kind == byFilter {
+				// items of the list are already among descendants
+				continue
+			}
+			next = append(next, s.apply(n)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func parseSteps(path string) ([]step, error) {
+	var steps []step
+	for path != "" {
+		recursive := false
+		switch {
+		case strings.HasPrefix(path, ".."):
+			recursive = true
+			path = path[2:]
+		case strings.HasPrefix(path, "."):
+			path = path[1:]
+		case strings.HasPrefix(path, "["):
+		default:
+			return nil, errors.Errorf("unexpected %q", path)
+		}
+
+		if strings.HasPrefix(path, "[") {
+			end := closingBracket(path)
+			if end < 0 {
+				return nil, errors.Errorf("bracket is not closed in %q", path)
+			}
+			s, err := parseBracket(strings.TrimSpace(path[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			s.recursive = recursive
+			steps = append(steps, s)
+			path = path[end+1:]
+			continue
+		}
+
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			end = len(path)
+		}
+		name := path[:end]
+		if name == "" {
+			return nil, errors.New("empty name")
+		}
+		s := step{recursive: recursive, kind: byName, names: []string{name}}
+		if name == "*" {
+			s = step{recursive: recursive, kind: byWildcard}
+		}
+		steps = append(steps, s)
+		path = path[end:]
+	}
+	return steps, nil
+}
+
+// closingBracket returns index of the bracket which closes the first one, quoted brackets are skipped.
+func closingBracket(path string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseBracket(content string) (step, error) {
+	switch {
+	case content == "*":
+		return step{kind: byWildcard}, nil
+	case strings.HasPrefix(content, "?"):
+		expr := strings.TrimSpace(content[1:])
+		if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+			return step{}, errors.Errorf("filter %q has to be in parentheses", content)
+		}
+		filter, err := parseFilter(expr[1 : len(expr)-1])
+		if err != nil {
+			return step{}, err
+		}
+		return step{kind: byFilter, filter: filter}, nil
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, "\""):
+		var names []string
+		for _, part := range splitOutsideQuotes(content, ",") {
+			name, ok := unquote(strings.TrimSpace(part))
+			if !ok {
+				return step{}, errors.Errorf("invalid name %q", part)
+			}
+			names = append(names, name)
+		}
+		return step{kind: byName, names: names}, nil
+	case strings.Contains(content, ":"):
+		parts := strings.Split(content, ":")
+		if len(parts) > 3 {
+			return step{}, errors.Errorf("invalid slice %q", content)
+		}
+		s := step{kind: bySlice}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return step{}, errors.Errorf("invalid slice %q", content)
+			}
+			s.slice[i] = &v
+		}
+		return s, nil
+	default:
+		s := step{kind: byIndex}
+		for _, part := range strings.Split(content, ",") {
+			v, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return step{}, errors.Errorf("invalid index %q", content)
+			}
+			s.indexes = append(s.indexes, v)
+		}
+		return s, nil
+	}
+}
+
+func (s step) apply(node interface{}) []interface{} {
+	switch s.kind {
+	case byName:
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		var out []interface{}
+		for _, name := range s.names {
+			if v, ok := m[name]; ok {
+				out = append(out, v)
+			}
+		}
+		return out
+	case byWildcard:
+		return children(node)
+	case byIndex:
+		list, ok := node.([]interface{})
+		if !ok {
+			return nil
+		}
+		var out []interface{}
+		for _, i := range s.indexes {
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				out = append(out, list[i])
+			}
+		}
+		return out
+	case bySlice:
+		list, ok := node.([]interface{})
+		if !ok {
+			return nil
+		}
+		return slice(list, s.slice)
+	case byFilter:
+		if m, ok := node.(map[string]interface{}); ok {
+			if s.filter.eval(m) {
+				return []interface{}{m}
+			}
+			return nil
+		}
+		var out []interface{}
+		for _, child := range children(node) {
+			if s.filter.eval(child) {
+				out = append(out, child)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func slice(list []interface{}, bounds [3]*int) []interface{} {
+	bound := func(v *int, def int) int {
+		if v == nil {
+			return def
+		}
+		i := *v
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > len(list) {
+			return len(list)
+		}
+		return i
+	}
+	start, end := bound(bounds[0], 0), bound(bounds[1], len(list))
+	stepSize := 1
+	if bounds[2] != nil && *bounds[2] > 0 {
+		stepSize = *bounds[2]
+	}
+	var out []interface{}
+	for i := start; i < end; i += stepSize {
+		out = append(out, list[i])
+	}
+	return out
+}
+
+// children returns object values in key order or array items.
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, len(keys))
+		for i, k := range keys {
+			out[i] = v[k]
+		}
+		return out
+	case []interface{}:
+		return v
+	default:
+		return nil
+	}
+}
+
+func descendants(nodes []interface{}) []interface{} {
+	var out []interface{}
+	for _, n := range nodes {
+		out = append(out, n)
+		out = append(out, descendants(children(n))...)
+	}
+	return out
+}
+
+func unquote(s string) (string, bool) {
+	if len(s) < 2 || (s[0] != '\'' && s[0] != '"') || s[len(s)-1] != s[0] {
+		return "", false
+	}
+	return s[1 : len(s)-1], true
+}
+
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	var quote byte
+	last := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[last:i])
+			last = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[last:])
+}
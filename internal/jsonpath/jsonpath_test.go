@@ -0,0 +1,90 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const store = `{
+	"name": "store",
+	"items": [
+		{"id": 1, "name": "book", "price": 8.95, "category": "book"},
+		{"id": 2, "name": "pen", "price": 12, "category": "stationery"},
+		{"id": 3, "name": "novel", "price": 22.99, "category": "book", "isbn": "0-553-21311-3"}
+	],
+	"owner": {"id": 10, "name": "John"}
+}`
+
+func TestEvaluateJSON(t *testing.T) {
+	for name, tc := range map[string]struct {
+		path     string
+		expected []interface{}
+	}{
+		"child":                  {path: "$.name", expected: []interface{}{"store"}},
+		"bracket child":          {path: "$['owner']['name']", expected: []interface{}{"John"}},
+		"union of names":         {path: "$.owner['id','name']", expected: []interface{}{10.0, "John"}},
+		"missing child":          {path: "$.missing", expected: nil},
+		"index":                  {path: "$.items[0].id", expected: []interface{}{1.0}},
+		"negative index":         {path: "$.items[-1].id", expected: []interface{}{3.0}},
+		"union of indexes":       {path: "$.items[0,2].id", expected: []interface{}{1.0, 3.0}},
+		"out of range index":     {path: "$.items[5]", expected: nil},
+		"slice":                  {path: "$.items[1:].id", expected: []interface{}{2.0, 3.0}},
+		"slice with step":        {path: "$.items[::2].id", expected: []interface{}{1.0, 3.0}},
+		"wildcard":               {path: "$.items[*].name", expected: []interface{}{"book", "pen", "novel"}},
+		"dot wildcard":           {path: "$.owner.*", expected: []interface{}{10.0, "John"}},
+		"recursive descent":      {path: "$..id", expected: []interface{}{1.0, 2.0, 3.0, 10.0}},
+		"recursive descent name": {path: "$..items[1].name", expected: []interface{}{"pen"}},
+		"filter by number":       {path: "$.items[?(@.price > 10)].id", expected: []interface{}{2.0, 3.0}},
+		"filter by string":       {path: "$.items[?(@.category == 'book')].id", expected: []interface{}{1.0, 3.0}},
+		"filter with and":        {path: "$.items[?(@.price > 10 && @.category == 'book')].id", expected: []interface{}{3.0}},
+		"filter with or":         {path: "$.items[?(@.price < 10 || @.price >= 22.99)].id", expected: []interface{}{1.0, 3.0}},
+		"filter by existence":    {path: "$.items[?(@.isbn)].id", expected: []interface{}{3.0}},
+		"filter not equal":       {path: "$.items[?(@.name != \"pen\")].id", expected: []interface{}{1.0, 3.0}},
+		"filter of object":       {path: "$.owner[?(@.id == 10)].name", expected: []interface{}{"John"}},
+		"recursive filter":       {path: "$..[?(@.price <= 12)].id", expected: []interface{}{1.0, 2.0}},
+		"filter of scalar":       {path: "$.name[?(@.id)]", expected: nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := EvaluateJSON(tc.path, []byte(store))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		path string
+		err  string
+	}{
+		"no root":             {path: "items[0]", err: "has to start with $"},
+		"empty name":          {path: "$.items.", err: "empty name"},
+		"unexpected step":     {path: "$items", err: "unexpected"},
+		"unclosed bracket":    {path: "$.items[0", err: "bracket is not closed"},
+		"invalid index":       {path: "$.items[a]", err: "invalid index"},
+		"invalid slice":       {path: "$.items[1:2:3:4]", err: "invalid slice"},
+		"invalid slice bound": {path: "$.items[a:]", err: "invalid slice"},
+		"invalid name":        {path: "$['id', name]", err: "invalid name"},
+		"filter without parentheses": {
+			path: "$.items[?@.id == 1]",
+			err:  "has to be in parentheses",
+		},
+		"filter without current node": {path: "$.items[?(1)]", err: "has to refer current node by @"},
+		"invalid filter literal":      {path: "$.items[?(@.id == x)]", err: "invalid filter literal"},
+		"invalid filter path":         {path: "$.items[?(@id == 1)]", err: "invalid filter path"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(tc.path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestEvaluateJSONInvalidDocument(t *testing.T) {
+	_, err := EvaluateJSON("$.id", []byte(`{"id":`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid json document")
+}
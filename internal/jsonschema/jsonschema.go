@@ -0,0 +1,318 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Validate validates decoded JSON value against decoded JSON schema and returns all violations.
+// It supports the commonly used subset of the draft 4-7 keywords: type, enum, const, properties, required,
+// additionalProperties, items, min/maxItems, uniqueItems, min/maxLength, pattern, format (is ignored),
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not and local $ref.
+func Validate(schema, value interface{}) []error {
+	v := validator{root: schema}
+	v.validate(schema, value, "$")
+	return v.errs
+}
+
+// ValidateJSON validates raw JSON document against raw JSON schema.
+func ValidateJSON(schema, data []byte) ([]error, error) {
+	var s, v interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, errors.Wrap(err, "invalid json schema")
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(err, "invalid json document")
+	}
+	return Validate(s, v), nil
+}
+
+type validator struct {
+	root interface{}
+	errs []error
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) validate(schema, value interface{}, path string) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		if b, ok := schema.(bool); ok && !b {
+			v.fail(path, "no value is allowed")
+		}
+		return
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		resolved, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%s", err)
+			return
+		}
+		v.validate(resolved, value, path)
+		return
+	}
+
+	v.validateType(s, value, path)
+	v.validateEnum(s, value, path)
+	v.validateCombinations(s, value, path)
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(s, val, path)
+	case []interface{}:
+		v.validateArray(s, val, path)
+	case string:
+		v.validateString(s, val, path)
+	case float64:
+		v.validateNumber(s, val, path)
+	}
+}
+
+func (v *validator) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, errors.Errorf("only local references are supported, got %s", ref)
+	}
+	node := v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("unable to resolve reference %s", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, errors.Errorf("unable to resolve reference %s", ref)
+		}
+	}
+	return node, nil
+}
+
+func (v *validator) validateType(s map[string]interface{}, value interface{}, path string) {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	default:
+		return
+	}
+	if nullable, _ := s["nullable"].(bool); nullable {
+		types = append(types, "null")
+	}
+
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return
+		}
+	}
+	v.fail(path, "expected type %s; actual %s", strings.Join(types, " or "), actual)
+}
+
+func (v *validator) validateEnum(s map[string]interface{}, value interface{}, path string) {
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		v.fail(path, "expected constant %v; actual %v", c, value)
+	}
+	enum, ok := s["enum"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) {
+			return
+		}
+	}
+	v.fail(path, "value %v is not one of %v", value, enum)
+}
+
+func (v *validator) validateCombinations(s map[string]interface{}, value interface{}, path string) {
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			v.validate(sub, value, path)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok && v.matches(anyOf, value) == 0 {
+		v.fail(path, "value doesn't match any schema of anyOf")
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		if n := v.matches(oneOf, value); n != 1 {
+			v.fail(path, "value matches %d schemas of oneOf instead of exactly one", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches([]interface{}{not}, value) == 1 {
+		v.fail(path, "value matches schema of not")
+	}
+}
+
+func (v *validator) matches(schemas []interface{}, value interface{}) int {
+	n := 0
+	for _, sub := range schemas {
+		nested := validator{root: v.root}
+		nested.validate(sub, value, "$")
+		if len(nested.errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (v *validator) validateObject(s map[string]interface{}, value map[string]interface{}, path string) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := value[name]; !ok {
+				v.fail(path, "required property %q is missing", name)
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if sub, ok := properties[k]; ok {
+			v.validate(sub, value[k], path+"."+k)
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(path, "additional property %q is not allowed", k)
+			}
+		case map[string]interface{}:
+			v.validate(additional, value[k], path+"."+k)
+		}
+	}
+
+	if min, ok := number(s["minProperties"]); ok && float64(len(value)) < min {
+		v.fail(path, "expected at least %v properties; actual %d", min, len(value))
+	}
+	if max, ok := number(s["maxProperties"]); ok && float64(len(value)) > max {
+		v.fail(path, "expected at most %v properties; actual %d", max, len(value))
+	}
+}
+
+func (v *validator) validateArray(s map[string]interface{}, value []interface{}, path string) {
+	switch items := s["items"].(type) {
+	case map[string]interface{}:
+		for i, item := range value {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case []interface{}:
+		for i, item := range value {
+			if i < len(items) {
+				v.validate(items[i], item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+
+	if min, ok := number(s["minItems"]); ok && float64(len(value)) < min {
+		v.fail(path, "expected at least %v items; actual %d", min, len(value))
+	}
+	if max, ok := number(s["maxItems"]); ok && float64(len(value)) > max {
+		v.fail(path, "expected at most %v items; actual %d", max, len(value))
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					v.fail(path, "items %d and %d are not unique", i, j)
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) validateString(s map[string]interface{}, value, path string) {
+	length := float64(utf8.RuneCountInString(value))
+	if min, ok := number(s["minLength"]); ok && length < min {
+		v.fail(path, "expected at least %v characters; actual %v", min, length)
+	}
+	if max, ok := number(s["maxLength"]); ok && length > max {
+		v.fail(path, "expected at most %v characters; actual %v", max, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			v.fail(path, "invalid pattern %s", pattern)
+		} else if !r.MatchString(value) {
+			v.fail(path, "value %q doesn't match pattern %s", value, pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(s map[string]interface{}, value float64, path string) {
+	if min, ok := number(s["minimum"]); ok {
+		if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive && value <= min {
+			v.fail(path, "expected value greater than %v; actual %v", min, value)
+		} else if value < min {
+			v.fail(path, "expected value at least %v; actual %v", min, value)
+		}
+	}
+	if max, ok := number(s["maximum"]); ok {
+		if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive && value >= max {
+			v.fail(path, "expected value less than %v; actual %v", max, value)
+		} else if value > max {
+			v.fail(path, "expected value at most %v; actual %v", max, value)
+		}
+	}
+	if min, ok := number(s["exclusiveMinimum"]); ok && value <= min {
+		v.fail(path, "expected value greater than %v; actual %v", min, value)
+	}
+	if max, ok := number(s["exclusiveMaximum"]); ok && value >= max {
+		v.fail(path, "expected value less than %v; actual %v", max, value)
+	}
+	if multiple, ok := number(s["multipleOf"]); ok && multiple != 0 {
+		if q := value / multiple; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "value %v is not multiple of %v", value, multiple)
+		}
+	}
+}
+
+func typeOf(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func number(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
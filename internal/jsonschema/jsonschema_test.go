@@ -0,0 +1,147 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petSchema = `{
+	"definitions": {
+		"id": {"type": "integer", "minimum": 1},
+		"named": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string", "minLength": 1}}},
+		"tagged": {"type": "object", "required": ["tag"]},
+		"pet~/1": {"type": "string"}
+	},
+	"allOf": [{"$ref": "#/definitions/named"}],
+	"type": "object",
+	"required": ["id"],
+	"properties": {
+		"id": {"$ref": "#/definitions/id"},
+		"name": {"type": "string"},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+		"kind": {"enum": ["cat", "dog"]},
+		"owner": {"oneOf": [{"$ref": "#/definitions/named"}, {"$ref": "#/definitions/tagged"}]},
+		"legacy": {"$ref": "#/definitions/pet~0~11"}
+	},
+	"additionalProperties": false
+}`
+
+func TestValidateJSON(t *testing.T) {
+	for name, tc := range map[string]struct {
+		schema   string
+		document string
+		errs     []string
+	}{
+		"valid document": {
+			schema:   petSchema,
+			document: `{"id": 1, "name": "Rex", "tags": ["a", "b"], "kind": "dog", "owner": {"name": "John"}}`,
+		},
+		"local $ref": {
+			schema:   petSchema,
+			document: `{"id": 0, "name": "Rex", "legacy": 1}`,
+			errs:     []string{"$.id: expected value at least 1; actual 0", "$.legacy: expected type string; actual integer"},
+		},
+		"allOf": {
+			schema:   petSchema,
+			document: `{"id": 1, "name": ""}`,
+			errs:     []string{"$.name: expected at least 1 characters; actual 0"},
+		},
+		"oneOf without match": {
+			schema:   petSchema,
+			document: `{"id": 1, "name": "Rex", "owner": {}}`,
+			errs:     []string{"$.owner: value matches 0 schemas of oneOf instead of exactly one"},
+		},
+		"oneOf with several matches": {
+			schema:   petSchema,
+			document: `{"id": 1, "name": "Rex", "owner": {"name": "John", "tag": "vip"}}`,
+			errs:     []string{"$.owner: value matches 2 schemas of oneOf instead of exactly one"},
+		},
+		"object violations": {
+			schema:   petSchema,
+			document: `{"name": "Rex", "color": "red"}`,
+			errs:     []string{`$: required property "id" is missing`, `$: additional property "color" is not allowed`},
+		},
+		"array violations": {
+			schema:   petSchema,
+			document: `{"id": 1, "name": "Rex", "tags": ["a", 1, "a"]}`,
+			errs: []string{
+				"$.tags[1]: expected type string; actual integer",
+				"$.tags: expected at most 2 items; actual 3",
+				"$.tags: items 0 and 2 are not unique",
+			},
+		},
+		"enum": {
+			schema:   petSchema,
+			document: `{"id": 1, "name": "Rex", "kind": "fish"}`,
+			errs:     []string{"$.kind: value fish is not one of [cat dog]"},
+		},
+		"anyOf and not": {
+			schema:   `{"anyOf": [{"type": "string"}, {"type": "integer"}], "not": {"const": 3}}`,
+			document: `3`,
+			errs:     []string{"$: value matches schema of not"},
+		},
+		"anyOf without match": {
+			schema:   `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`,
+			document: `1.5`,
+			errs:     []string{"$: value doesn't match any schema of anyOf"},
+		},
+		"number keywords": {
+			schema:   `{"type": "number", "exclusiveMinimum": 1, "maximum": 10, "exclusiveMaximum": true, "multipleOf": 2}`,
+			document: `10`,
+			errs:     []string{"$: expected value less than 10; actual 10"},
+		},
+		"nullable": {
+			schema:   `{"type": "string", "nullable": true}`,
+			document: `null`,
+		},
+		"pattern": {
+			schema:   `{"type": "string", "pattern": "^[a-z]+$"}`,
+			document: `"Rex"`,
+			errs:     []string{`$: value "Rex" doesn't match pattern ^[a-z]+$`},
+		},
+		"false schema": {
+			schema:   `{"properties": {"id": false}}`,
+			document: `{"id": 1}`,
+			errs:     []string{"$.id: no value is allowed"},
+		},
+		"unresolved $ref": {
+			schema:   `{"$ref": "#/definitions/missing"}`,
+			document: `{}`,
+			errs:     []string{"$: unable to resolve reference #/definitions/missing"},
+		},
+		"remote $ref": {
+			schema:   `{"$ref": "http://example.com/pet.json"}`,
+			document: `{}`,
+			errs:     []string{"$: only local references are supported, got http://example.com/pet.json"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			errs, err := ValidateJSON([]byte(tc.schema), []byte(tc.document))
+			require.NoError(t, err)
+			var actual []string
+			for _, e := range errs {
+				actual = append(actual, e.Error())
+			}
+			assert.Equal(t, tc.errs, actual)
+		})
+	}
+}
+
+func TestValidateJSONMalformed(t *testing.T) {
+	for name, tc := range map[string]struct {
+		schema   string
+		document string
+		err      string
+	}{
+		"malformed schema":   {schema: `{"type":`, document: `{}`, err: "invalid json schema"},
+		"malformed document": {schema: `{}`, document: `{"id": 1`, err: "invalid json document"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ValidateJSON([]byte(tc.schema), []byte(tc.document))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package mustache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	openTag  = "{{"
+	closeTag = "}}"
+)
+
+type kind int
+
+const (
+	text kind = iota
+	variable
+	section
+	inverted
+)
+
+type node struct {
+	kind     kind
+	value    string
+	children []node
+}
+
+// Template is parsed mustache template.
+type Template struct {
+	nodes []node
+}
+
+// Parse parses mustache template, it supports variables, sections, inverted sections and comments.
+func Parse(template string) (*Template, error) {
+	nodes, _, _, err := parse(template, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Template{nodes: nodes}, nil
+}
+
+// Render renders template with data, where data is a composition of maps, slices and scalar values.
+func (t *Template) Render(data interface{}) string {
+	out := strings.Builder{}
+	render(&out, t.nodes, []interface{}{data})
+	return out.String()
+}
+
+// parse reads nodes until closing tag of the section is found, it returns the rest of the template after the tag.
+func parse(template, sectionName string) ([]node, string, string, error) {
+	var nodes []node
+	for {
+		start := strings.Index(template, openTag)
+		if start < 0 {
+			if sectionName != "" {
+				return nil, "", "", errors.Errorf("section %q is not closed", sectionName)
+			}
+			if template != "" {
+				nodes = append(nodes, node{kind: text, value: template})
+			}
+			return nodes, "", "", nil
+		}
+		if start > 0 {
+			nodes = append(nodes, node{kind: text, value: template[:start]})
+		}
+		template = template[start+len(openTag):]
+
+		closing := closeTag
+		if strings.HasPrefix(template, "{") {
+			closing = "}" + closeTag
+			template = template[1:]
+		}
+		end := strings.Index(template, closing)
+		if end < 0 {
+			return nil, "", "", errors.Errorf("tag is not closed at %q", shorten(template))
+		}
+		tag := strings.TrimSpace(template[:end])
+		template = template[end+len(closing):]
+
+		if tag == "" {
+			return nil, "", "", errors.New("empty tag")
+		}
+		switch tag[0] {
+		case '!':
+		case '#', '^':
+			name := strings.TrimSpace(tag[1:])
+			children, rest, closed, err := parse(template, name)
+			if err != nil {
+				return nil, "", "", err
+			}
+			if closed != name {
+				return nil, "", "", errors.Errorf("section %q is closed by %q", name, closed)
+			}
+			k := section
+			if tag[0] == '^' {
+				k = inverted
+			}
+			nodes = append(nodes, node{kind: k, value: name, children: children})
+			template = rest
+		case '/':
+			name := strings.TrimSpace(tag[1:])
+			if sectionName == "" {
+				return nil, "", "", errors.Errorf("unexpected closing section %q", name)
+			}
+			return nodes, template, name, nil
+		case '&':
+			nodes = append(nodes, node{kind: variable, value: strings.TrimSpace(tag[1:])})
+		default:
+			nodes = append(nodes, node{kind: variable, value: tag})
+		}
+	}
+}
+
+func render(out *strings.Builder, nodes []node, stack []interface{}) {
+	for _, n := range nodes {
+		switch n.kind {
+		case text:
+			out.WriteString(n.value)
+		case variable:
+			v := lookup(stack, n.value)
+			if v != nil {
+				out.WriteString(format(v))
+			}
+		case section:
+			v := lookup(stack, n.value)
+			if !truthy(v) {
+				continue
+			}
+			if list, ok := v.([]interface{}); ok {
+				for _, item := range list {
+					render(out, n.children, append(stack, item))
+				}
+				continue
+			}
+			render(out, n.children, append(stack, v))
+		case inverted:
+			if truthy(lookup(stack, n.value)) {
+				continue
+			}
+			render(out, n.children, stack)
+		}
+	}
+}
+
+// lookup resolves dotted name, the first part of the name is searched from the top of context stack.
+func lookup(stack []interface{}, name string) interface{} {
+	if name == "." {
+		return stack[len(stack)-1]
+	}
+	parts := strings.Split(name, ".")
+	for i := len(stack) - 1; i >= 0; i-- {
+		v, ok := field(stack[i], parts[0])
+		if !ok {
+			continue
+		}
+		for _, p := range parts[1:] {
+			v, ok = field(v, p)
+			if !ok {
+				return nil
+			}
+		}
+		return v
+	}
+	return nil
+}
+
+func field(v interface{}, name string) (interface{}, bool) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		f, ok := value[name]
+		return f, ok
+	case []interface{}:
+		i, err := strconv.Atoi(name)
+		if err != nil || i < 0 || i >= len(value) {
+			return nil, false
+		}
+		return value[i], true
+	default:
+		return nil, false
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return value
+	case string:
+		return value != ""
+	case []interface{}:
+		return len(value) > 0
+	default:
+		return true
+	}
+}
+
+func format(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []interface{}:
+		parts := make([]string, len(value))
+		for i, item := range value {
+			parts[i] = format(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func shorten(s string) string {
+	if len(s) > 20 {
+		return s[:20] + "..."
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package mustache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	data := map[string]interface{}{
+		"name":  "Rex",
+		"empty": "",
+		"ok":    true,
+		"count": 2.5,
+		"tags":  []interface{}{"a", "b"},
+		"pets": []interface{}{
+			map[string]interface{}{"name": "Tom", "owner": map[string]interface{}{"name": "John"}},
+			map[string]interface{}{"name": "Jerry"},
+		},
+		"owner": map[string]interface{}{"name": "Ann"},
+	}
+
+	for name, tc := range map[string]struct {
+		template string
+		expected string
+	}{
+		"text":                     {template: "plain text", expected: "plain text"},
+		"empty template":           {template: "", expected: ""},
+		"variable":                 {template: "Hello {{ name }}!", expected: "Hello Rex!"},
+		"triple mustache":          {template: "{{{name}}} {{& name}}", expected: "Rex Rex"},
+		"missing variable":         {template: "[{{ missing }}]", expected: "[]"},
+		"dotted name":              {template: "{{ owner.name }}", expected: "Ann"},
+		"list index":               {template: "{{ tags.1 }}", expected: "b"},
+		"list":                     {template: "{{ tags }}", expected: "[a, b]"},
+		"number":                   {template: "{{ count }}", expected: "2.5"},
+		"comment":                  {template: "a{{! comment }}b", expected: "ab"},
+		"section of list":          {template: "{{#tags}}<{{.}}>{{/tags}}", expected: "<a><b>"},
+		"section of object":        {template: "{{#owner}}{{name}}{{/owner}}", expected: "Ann"},
+		"section of true":          {template: "{{#ok}}yes{{/ok}}", expected: "yes"},
+		"section of falsy value":   {template: "{{#empty}}yes{{/empty}}{{#missing}}yes{{/missing}}", expected: ""},
+		"inverted section":         {template: "{{^empty}}no{{/empty}}{{^ok}}no{{/ok}}", expected: "no"},
+		"nested sections":          {template: "{{#pets}}{{name}}:{{#owner}}{{name}}{{/owner}}{{^vet}}-{{/vet}};{{/pets}}", expected: "Tom:John-;Jerry:Ann-;"},
+		"lookup of parent context": {template: "{{#pets}}{{name}}/{{owner.name}} {{/pets}}", expected: "Tom/John Jerry/Ann "},
+		"scalar of parent context": {template: "{{#pets}}{{count}}{{/pets}}", expected: "2.52.5"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := Parse(tc.template)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tmpl.Render(data))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		template string
+		err      string
+	}{
+		"unclosed section":          {template: "{{#pets}}{{name}}", err: `section "pets" is not closed`},
+		"unclosed inverted section": {template: "{{^pets}}none", err: `section "pets" is not closed`},
+		"unclosed nested section":   {template: "{{#pets}}{{#owner}}{{/pets}}", err: `section "owner" is closed by "pets"`},
+		"mismatched section":        {template: "{{#pets}}{{/dogs}}", err: `section "pets" is closed by "dogs"`},
+		"unexpected closing":        {template: "text{{/pets}}", err: `unexpected closing section "pets"`},
+		"unclosed tag":              {template: "Hello {{ name", err: `tag is not closed at " name"`},
+		"unclosed triple mustache":  {template: "{{{name}}", err: "tag is not closed"},
+		"empty tag":                 {template: "{{ }}", err: "empty tag"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tc.template)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestRequestData(t *testing.T) {
+	tmpl, err := Parse("{{request.method}} {{request.path}} {{request.pathParameters.id.0}} " +
+		"{{request.queryStringParameters.q}} {{request.headers.X-Id.0}} {{request.cookies.session}} {{request.body}}")
+	require.NoError(t, err)
+
+	data := Request{
+		Method:                "POST",
+		Path:                  "/pets/1",
+		PathParameters:        map[string][]string{"id": {"1"}},
+		QueryStringParameters: map[string][]string{"q": {"a", "b"}},
+		Headers:               map[string][]string{"X-Id": {"some-id"}},
+		Cookies:               map[string]string{"session": "s1"},
+		Body:                  `{"name":"Rex"}`,
+	}.Data()
+	assert.Equal(t, `POST /pets/1 1 [a, b] some-id s1 {"name":"Rex"}`, tmpl.Render(data))
+	assert.NotEmpty(t, data["uuid"])
+	assert.NotEmpty(t, data["now_iso_8601"])
+	assert.NotEmpty(t, data["now_epoch"])
+}
@@ -0,0 +1,55 @@
+package mustache
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request is HTTP request model available in templates the same way as mock server app provides it,
+// for example {{ request.pathParameters.id.0 }} or {{ request.headers.X-Correlation-Id.0 }}.
+type Request struct {
+	Method                string
+	Path                  string
+	PathParameters        map[string][]string
+	QueryStringParameters map[string][]string
+	Headers               map[string][]string
+	Cookies               map[string]string
+	Body                  string
+}
+
+// Data returns template data which contains the request and helper values.
+func (r Request) Data() map[string]interface{} {
+	cookies := map[string]interface{}{}
+	for k, v := range r.Cookies {
+		cookies[k] = v
+	}
+	now := time.Now()
+	return map[string]interface{}{
+		"request": map[string]interface{}{
+			"method":                r.Method,
+			"path":                  r.Path,
+			"pathParameters":        multiValues(r.PathParameters),
+			"queryStringParameters": multiValues(r.QueryStringParameters),
+			"headers":               multiValues(r.Headers),
+			"cookies":               cookies,
+			"body":                  r.Body,
+		},
+		"uuid":         uuid.NewString(),
+		"now_iso_8601": now.UTC().Format(time.RFC3339),
+		"now_epoch":    strconv.FormatInt(now.Unix(), 10),
+	}
+}
+
+func multiValues(m map[string][]string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, values := range m {
+		list := make([]interface{}, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		out[k] = list
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"sort"
+)
+
+// mediaExample returns example of media type object: example, the first of examples or generated by the schema.
+func mediaExample(media map[string]interface{}) interface{} {
+	if example, ok := media["example"]; ok {
+		return example
+	}
+	if examples, ok := media["examples"].(map[string]interface{}); ok && len(examples) > 0 {
+		keys := make([]string, 0, len(examples))
+		for k := range examples {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if e, ok := examples[keys[0]].(map[string]interface{}); ok {
+			if value, ok := e["value"]; ok {
+				return value
+			}
+		}
+	}
+	if schema, ok := media["schema"]; ok {
+		return SchemaExample(schema)
+	}
+	return nil
+}
+
+// SchemaExample generates example value by the schema with resolved references: example, default, the first of enum
+// or a value of the schema type are used.
+func SchemaExample(schema interface{}) interface{} {
+	return schemaExample(schema, 0)
+}
+
+// maxExampleDepth protects from endless examples of recursive schemas.
+const maxExampleDepth = 8
+
+func schemaExample(schema interface{}, depth int) interface{} {
+	s, ok := schema.(map[string]interface{})
+	if !ok || depth > maxExampleDepth {
+		return nil
+	}
+	if example, ok := s["example"]; ok {
+		return example
+	}
+	if def, ok := s["default"]; ok {
+		return def
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		list, ok := s[key].([]interface{})
+		if !ok || len(list) == 0 {
+			continue
+		}
+		if key != "allOf" {
+			return schemaExample(list[0], depth+1)
+		}
+		merged := map[string]interface{}{}
+		for _, sub := range list {
+			if m, ok := schemaExample(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range m {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+
+	switch typeOf(s) {
+	case "object":
+		out := map[string]interface{}{}
+		properties, _ := s["properties"].(map[string]interface{})
+		for name, property := range properties {
+			out[name] = schemaExample(property, depth+1)
+		}
+		return out
+	case "array":
+		return []interface{}{schemaExample(s["items"], depth+1)}
+	case "integer":
+		return minimum(s, 0)
+	case "number":
+		return minimum(s, 0.0)
+	case "boolean":
+		return true
+	case "string":
+		return stringExample(s)
+	default:
+		return nil
+	}
+}
+
+func typeOf(s map[string]interface{}) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if _, ok := s["properties"]; ok {
+		return "object"
+	}
+	if _, ok := s["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+func minimum(s map[string]interface{}, def float64) float64 {
+	if min, ok := s["minimum"].(float64); ok {
+		return min
+	}
+	return def
+}
+
+func stringExample(s map[string]interface{}) string {
+	switch s["format"] {
+	case "date":
+		return "2021-01-01"
+	case "date-time":
+		return "2021-01-01T00:00:00Z"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "http://example.com"
+	default:
+		return "string"
+	}
+}
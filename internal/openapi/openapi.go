@@ -0,0 +1,424 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is parsed OpenAPI 3 document, both JSON and YAML representations are supported.
+type Document struct {
+	root       map[string]interface{}
+	basePath   string
+	operations []*Operation
+}
+
+// Operation is an operation of the document with resolved local references.
+type Operation struct {
+	ID          string
+	Method      string
+	Path        string
+	Parameters  []Parameter
+	RequestBody *RequestBody
+	Responses   map[string]Response
+}
+
+// Parameter is an operation parameter, In is one of path, query, header or cookie.
+type Parameter struct {
+	Name     string
+	In       string
+	Required bool
+	Schema   interface{}
+}
+
+// RequestBody contains schemas of the body by media type.
+type RequestBody struct {
+	Required bool
+	Content  map[string]interface{}
+}
+
+// Response contains example body by media type.
+type Response struct {
+	Content map[string]Example
+}
+
+// Example is a body example of the media type, it's taken from the document or generated by the schema.
+type Example struct {
+	Value interface{}
+}
+
+// Load loads the document from URL, file path or the document itself.
+func Load(specURLOrPayload string) (*Document, error) {
+	data, err := read(specURLOrPayload)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load openapi document")
+	}
+	return Parse(data)
+}
+
+// IsPayload checks if the value is the document itself instead of its location.
+func IsPayload(specURLOrPayload string) bool {
+	s := strings.TrimSpace(specURLOrPayload)
+	return strings.HasPrefix(s, "{") || strings.Contains(s, "\n")
+}
+
+func read(specURLOrPayload string) ([]byte, error) {
+	if IsPayload(specURLOrPayload) {
+		return []byte(specURLOrPayload), nil
+	}
+	u, err := url.Parse(specURLOrPayload)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		rs, err := http.Get(specURLOrPayload)
+		if err != nil {
+			return nil, err
+		}
+		defer rs.Body.Close()
+		if rs.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("unexpected http status %d", rs.StatusCode)
+		}
+		return ioutil.ReadAll(rs.Body)
+	}
+	if err == nil && u.Scheme == "file" {
+		return ioutil.ReadFile(u.Path)
+	}
+	return ioutil.ReadFile(specURLOrPayload)
+}
+
+// Parse parses JSON or YAML document.
+func Parse(data []byte) (*Document, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "invalid openapi document")
+	}
+	root, ok := toJSONValue(raw).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid openapi document: root has to be an object")
+	}
+	if version, _ := root["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, errors.Errorf("invalid openapi document: version 3.x is required, got %q", version)
+	}
+
+	d := &Document{root: root, basePath: basePath(root)}
+	if err := d.parseOperations(); err != nil {
+		return nil, errors.Wrap(err, "invalid openapi document")
+	}
+	return d, nil
+}
+
+// Operations returns all operations in order of paths and methods.
+func (d *Document) Operations() []*Operation {
+	return d.operations
+}
+
+// Operation returns operation by its operationId.
+func (d *Document) Operation(id string) (*Operation, bool) {
+	for _, o := range d.operations {
+		if o.ID == id {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// BasePath returns path of the first server URL, it prefixes paths of all operations.
+func (d *Document) BasePath() string {
+	return d.basePath
+}
+
+// Resolve resolves local reference like #/components/schemas/Pet.
+func (d *Document) Resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, errors.Errorf("only local references are supported, got %s", ref)
+	}
+	var node interface{} = d.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("unable to resolve reference %s", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, errors.Errorf("unable to resolve reference %s", ref)
+		}
+	}
+	return node, nil
+}
+
+// Schema resolves all local references of the schema, recursive references are replaced by empty schema.
+func (d *Document) Schema(schema interface{}) interface{} {
+	return d.inline(schema, map[string]bool{})
+}
+
+func (d *Document) inline(node interface{}, visiting map[string]bool) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if visiting[ref] {
+				return map[string]interface{}{}
+			}
+			resolved, err := d.Resolve(ref)
+			if err != nil {
+				return v
+			}
+			visiting[ref] = true
+			defer delete(visiting, ref)
+			return d.inline(resolved, visiting)
+		}
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = d.inline(item, visiting)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = d.inline(item, visiting)
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+func (d *Document) parseOperations() error {
+	paths, _ := d.root["paths"].(map[string]interface{})
+	keys := make([]string, 0, len(paths))
+	for k := range paths {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, path := range keys {
+		item, ok := d.Schema(paths[path]).(map[string]interface{})
+		if !ok {
+			return errors.Errorf("path %s has to be an object", path)
+		}
+		common := d.parameters(item["parameters"])
+		for _, method := range methods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			o := &Operation{
+				Method:     strings.ToUpper(method),
+				Path:       d.basePath + path,
+				Parameters: mergeParameters(common, d.parameters(op["parameters"])),
+				Responses:  d.responses(op["responses"]),
+			}
+			o.ID, _ = op["operationId"].(string)
+			if o.ID == "" {
+				o.ID = method + " " + path
+			}
+			if body, ok := op["requestBody"].(map[string]interface{}); ok {
+				o.RequestBody = &RequestBody{Content: map[string]interface{}{}}
+				o.RequestBody.Required, _ = body["required"].(bool)
+				content, _ := body["content"].(map[string]interface{})
+				for mediaType, media := range content {
+					m, _ := media.(map[string]interface{})
+					o.RequestBody.Content[mediaType] = m["schema"]
+				}
+			}
+			d.operations = append(d.operations, o)
+		}
+	}
+	return nil
+}
+
+func (d *Document) parameters(node interface{}) []Parameter {
+	list, _ := node.([]interface{})
+	out := make([]Parameter, 0, len(list))
+	for _, item := range list {
+		p, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		param := Parameter{Schema: p["schema"]}
+		param.Name, _ = p["name"].(string)
+		param.In, _ = p["in"].(string)
+		param.Required, _ = p["required"].(bool)
+		out = append(out, param)
+	}
+	return out
+}
+
+// mergeParameters overrides path item parameters by operation parameters with the same name and location.
+func mergeParameters(common, own []Parameter) []Parameter {
+	out := append([]Parameter{}, own...)
+	for _, c := range common {
+		overridden := false
+		for _, o := range own {
+			if o.Name == c.Name && o.In == c.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (d *Document) responses(node interface{}) map[string]Response {
+	responses, _ := node.(map[string]interface{})
+	out := make(map[string]Response, len(responses))
+	for status, item := range responses {
+		r, _ := item.(map[string]interface{})
+		content, _ := r["content"].(map[string]interface{})
+		response := Response{Content: map[string]Example{}}
+		for mediaType, media := range content {
+			m, _ := media.(map[string]interface{})
+			response.Content[mediaType] = Example{Value: mediaExample(m)}
+		}
+		out[status] = response
+	}
+	return out
+}
+
+// ExampleResponse is a response of the operation built from the example.
+type ExampleResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        interface{}
+}
+
+// ExampleResponse returns example response for the status code, Operation.DefaultStatus is used for 0 status.
+func (o *Operation) ExampleResponse(status int) (ExampleResponse, error) {
+	if status == 0 {
+		status = o.DefaultStatus()
+	}
+	r, code, ok := o.Response(status)
+	if !ok {
+		return ExampleResponse{}, errors.Errorf("operation %s has no response with status %d", o.ID, status)
+	}
+	rs := ExampleResponse{StatusCode: code}
+	if mediaType, example, ok := r.MediaType(); ok {
+		rs.ContentType = mediaType
+		rs.Body = example.Value
+	}
+	return rs, nil
+}
+
+// Response returns response by status code, "default" response is used if there is no such status.
+// The second value is the status code the response has to be returned with.
+func (o *Operation) Response(status int) (Response, int, bool) {
+	if r, ok := o.Responses[strconv.Itoa(status)]; ok {
+		return r, status, true
+	}
+	if r, ok := o.Responses[fmt.Sprintf("%dXX", status/100)]; ok {
+		return r, status, true
+	}
+	if r, ok := o.Responses["default"]; ok {
+		return r, status, true
+	}
+	return Response{}, 0, false
+}
+
+// DefaultStatus returns the lowest 2xx status code of the operation responses, or the lowest one if there is no 2xx.
+func (o *Operation) DefaultStatus() int {
+	lowest, lowestSuccess := 0, 0
+	for status := range o.Responses {
+		code, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(status), "XX", "00"))
+		if err != nil {
+			continue
+		}
+		if lowest == 0 || code < lowest {
+			lowest = code
+		}
+		if code >= 200 && code < 300 && (lowestSuccess == 0 || code < lowestSuccess) {
+			lowestSuccess = code
+		}
+	}
+	switch {
+	case lowestSuccess != 0:
+		return lowestSuccess
+	case lowest != 0:
+		return lowest
+	default:
+		return http.StatusOK
+	}
+}
+
+// MediaType returns JSON media type of the response if any, otherwise the first one in alphabetical order.
+func (r Response) MediaType() (string, Example, bool) {
+	keys := make([]string, 0, len(r.Content))
+	for k := range r.Content {
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return "", Example{}, false
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "application/json" || strings.HasSuffix(k, "+json") {
+			return k, r.Content[k], true
+		}
+	}
+	return keys[0], r.Content[keys[0]], true
+}
+
+func basePath(root map[string]interface{}) string {
+	servers, _ := root["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]interface{})
+	raw, _ := server["url"].(string)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// toJSONValue converts decoded YAML to the values encoding/json decodes JSON to.
+func toJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = toJSONValue(item)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[fmt.Sprint(k)] = toJSONValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = toJSONValue(item)
+		}
+		return out
+	case nil, bool, string, float64:
+		return val
+	case int:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float32:
+		return float64(val)
+	default:
+		if data, err := json.Marshal(val); err == nil {
+			var out interface{}
+			if json.Unmarshal(data, &out) == nil {
+				return out
+			}
+		}
+		return val
+	}
+}
@@ -0,0 +1,304 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petstore = `
+openapi: 3.0.0
+servers:
+  - url: http://petstore.example.com/v1/
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        default:
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: Created
+        '4XX':
+          content:
+            application/json:
+              example: {code: 400, message: bad request}
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      parameters:
+        - name: X-Request-ID
+          in: header
+          required: true
+          schema:
+            type: string
+            format: uuid
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Dog'
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          minimum: 1
+        name:
+          type: string
+        owner:
+          oneOf:
+            - $ref: '#/components/schemas/Person'
+            - type: string
+    Person:
+      type: object
+      properties:
+        name:
+          type: string
+          example: John
+        friend:
+          $ref: '#/components/schemas/Person'
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          properties:
+            breed:
+              type: string
+              enum: [beagle, collie]
+    Error:
+      type: object
+      properties:
+        code:
+          type: integer
+        message:
+          type: string
+          default: failure
+`
+
+func TestParse(t *testing.T) {
+	d, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+	assert.Equal(t, "/v1", d.BasePath())
+
+	var operations []string
+	for _, o := range d.Operations() {
+		operations = append(operations, o.String())
+	}
+	assert.Equal(t, []string{"listPets (GET /v1/pets)", "createPet (POST /v1/pets)", "get /pets/{petId} (GET /v1/pets/{petId})"}, operations)
+
+	o, ok := d.Operation("get /pets/{petId}")
+	require.True(t, ok)
+	require.Len(t, o.Parameters, 2)
+	assert.Equal(t, "X-Request-ID", o.Parameters[0].Name)
+	assert.Equal(t, "petId", o.Parameters[1].Name)
+
+	_, ok = d.Operation("missing")
+	assert.False(t, ok)
+}
+
+func TestParseMalformed(t *testing.T) {
+	for name, tc := range map[string]struct {
+		document string
+		err      string
+	}{
+		"invalid yaml":         {document: "openapi: [3.0.0", err: "invalid openapi document"},
+		"root is not object":   {document: "- openapi", err: "root has to be an object"},
+		"missing version":      {document: "paths: {}", err: `version 3.x is required, got ""`},
+		"swagger 2 document":   {document: "swagger: '2.0'\nopenapi: '2.0'", err: `version 3.x is required, got "2.0"`},
+		"path is not object":   {document: "openapi: 3.0.0\npaths:\n  /pets: 1", err: "path /pets has to be an object"},
+		"invalid json payload": {document: `{"openapi": "3.0.0"`, err: "invalid openapi document"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.document))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestExampleResponse(t *testing.T) {
+	d, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		operationID string
+		status      int
+		expected    ExampleResponse
+		err         string
+	}{
+		// recursive reference of Person.friend is replaced by empty schema which has no example
+		"$ref with recursive schema": {
+			operationID: "listPets",
+			expected: ExampleResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: []interface{}{
+				map[string]interface{}{"id": 1.0, "name": "string", "owner": map[string]interface{}{"name": "John", "friend": nil}},
+			}},
+		},
+		"default response": {
+			operationID: "listPets",
+			status:      http.StatusInternalServerError,
+			expected: ExampleResponse{StatusCode: http.StatusInternalServerError, ContentType: "application/json", Body: map[string]interface{}{
+				"code": 0.0, "message": "failure",
+			}},
+		},
+		"response without content": {
+			operationID: "createPet",
+			expected:    ExampleResponse{StatusCode: http.StatusCreated},
+		},
+		"status range with example": {
+			operationID: "createPet",
+			status:      http.StatusConflict,
+			expected: ExampleResponse{StatusCode: http.StatusConflict, ContentType: "application/json", Body: map[string]interface{}{
+				"code": 400.0, "message": "bad request",
+			}},
+		},
+		"allOf": {
+			operationID: "get /pets/{petId}",
+			expected: ExampleResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: map[string]interface{}{
+				"id": 1.0, "name": "string", "breed": "beagle", "owner": map[string]interface{}{"name": "John", "friend": nil},
+			}},
+		},
+		"missing status": {
+			operationID: "get /pets/{petId}",
+			status:      http.StatusNotFound,
+			err:         "operation get /pets/{petId} has no response with status 404",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			o, ok := d.Operation(tc.operationID)
+			require.True(t, ok)
+			rs, err := o.ExampleResponse(tc.status)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rs)
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	d, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		operationID string
+		request     Request
+		errs        []string
+	}{
+		"valid query": {
+			operationID: "listPets",
+			request:     Request{Path: "/v1/pets", Query: map[string][]string{"limit": {"10"}}},
+		},
+		"invalid query": {
+			operationID: "listPets",
+			request:     Request{Path: "/v1/pets", Query: map[string][]string{"limit": {"1000"}}},
+			errs:        []string{"invalid query parameter limit: $: expected value at most 100; actual 1000"},
+		},
+		"path mismatch": {
+			operationID: "listPets",
+			request:     Request{Path: "/pets"},
+			errs:        []string{"path /pets does not match /v1/pets"},
+		},
+		"path and header parameters": {
+			operationID: "get /pets/{petId}",
+			request:     Request{Path: "/v1/pets/abc", Headers: http.Header{}},
+			errs: []string{
+				"missing required header parameter X-Request-ID",
+				"invalid path parameter petId: $: expected type integer; actual string",
+			},
+		},
+		"valid body with $ref and oneOf": {
+			operationID: "createPet",
+			request: Request{
+				Path:    "/v1/pets",
+				Headers: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+				Body:    []byte(`{"id": 1, "name": "Rex", "owner": {"name": "John"}}`),
+			},
+		},
+		"invalid body": {
+			operationID: "createPet",
+			request: Request{
+				Path:    "/v1/pets",
+				Headers: http.Header{"Content-Type": {"application/json"}},
+				Body:    []byte(`{"id": 0, "owner": 1}`),
+			},
+			errs: []string{
+				`invalid body: $: required property "name" is missing`,
+				"invalid body: $.id: expected value at least 1; actual 0",
+				"invalid body: $.owner: value matches 0 schemas of oneOf instead of exactly one",
+			},
+		},
+		"malformed body": {
+			operationID: "createPet",
+			request: Request{
+				Path:    "/v1/pets",
+				Headers: http.Header{"Content-Type": {"application/json"}},
+				Body:    []byte(`{"id": 1`),
+			},
+			errs: []string{"invalid JSON body: unexpected end of JSON input"},
+		},
+		"missing body": {
+			operationID: "createPet",
+			request:     Request{Path: "/v1/pets", Headers: http.Header{}},
+			errs:        []string{"missing required request body"},
+		},
+		"unexpected content type": {
+			operationID: "createPet",
+			request: Request{
+				Path:    "/v1/pets",
+				Headers: http.Header{"Content-Type": {"text/plain"}},
+				Body:    []byte(`Rex`),
+			},
+			errs: []string{"unexpected Content-Type text/plain, expected one of [application/json]"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			o, ok := d.Operation(tc.operationID)
+			require.True(t, ok)
+			var actual []string
+			for _, err := range d.ValidateRequest(o, tc.request) {
+				actual = append(actual, err.Error())
+			}
+			assert.Equal(t, tc.errs, actual)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/jsonschema"
+)
+
+var pathParameterPattern = regexp.MustCompile(`\{([^/{}]+)}`)
+
+// Request is HTTP request to be validated against an operation.
+type Request struct {
+	Path    string
+	Query   map[string][]string
+	Headers http.Header
+	Cookies map[string]string
+	Body    []byte
+}
+
+// ValidateRequest validates path, query, header and cookie parameters and JSON body of the request against
+// the operation schemas and returns all violations.
+func (d *Document) ValidateRequest(o *Operation, rq Request) []error {
+	var errs []error
+	pathParams, ok := pathParameters(o.Path, rq.Path)
+	if !ok {
+		errs = append(errs, errors.Errorf("path %s does not match %s", rq.Path, o.Path))
+	}
+
+	for _, p := range o.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if !ok {
+				continue
+			}
+			if v, found := pathParams[p.Name]; found {
+				values = []string{v}
+			}
+		case "query":
+			values = rq.Query[p.Name]
+		case "header":
+			values = rq.Headers.Values(p.Name)
+		case "cookie":
+			if v, found := rq.Cookies[p.Name]; found {
+				values = []string{v}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if p.Required || p.In == "path" {
+				errs = append(errs, errors.Errorf("missing required %s parameter %s", p.In, p.Name))
+			}
+			continue
+		}
+		if p.Schema == nil {
+			continue
+		}
+		schema := d.Schema(p.Schema)
+		for _, err := range jsonschema.Validate(schema, parameterValue(schema, p.In, values)) {
+			errs = append(errs, errors.Wrapf(err, "invalid %s parameter %s", p.In, p.Name))
+		}
+	}
+
+	return append(errs, d.validateBody(o, rq)...)
+}
+
+func (d *Document) validateBody(o *Operation, rq Request) []error {
+	if len(rq.Body) == 0 {
+		if o.RequestBody != nil && o.RequestBody.Required {
+			return []error{errors.New("missing required request body")}
+		}
+		return nil
+	}
+	if o.RequestBody == nil || len(o.RequestBody.Content) == 0 {
+		return nil
+	}
+
+	contentType := rq.Headers.Get("Content-Type")
+	if contentType == "" {
+		return []error{errors.Errorf("missing Content-Type header, expected one of %v", mediaTypes(o.RequestBody.Content))}
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []error{errors.Wrapf(err, "invalid Content-Type header %s", contentType)}
+	}
+	schema, ok := mediaTypeSchema(o.RequestBody.Content, mediaType)
+	if !ok {
+		return []error{errors.Errorf("unexpected Content-Type %s, expected one of %v", mediaType, mediaTypes(o.RequestBody.Content))}
+	}
+	if schema == nil || !isJSON(mediaType) {
+		return nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(rq.Body, &body); err != nil {
+		return []error{errors.Wrap(err, "invalid JSON body")}
+	}
+	var errs []error
+	for _, err := range jsonschema.Validate(d.Schema(schema), body) {
+		errs = append(errs, errors.Wrap(err, "invalid body"))
+	}
+	return errs
+}
+
+// pathParameters extracts parameters from actual path by path template, for example "/pets/{petId}".
+func pathParameters(template, actual string) (map[string]string, bool) {
+	var names []string
+	pattern := strings.Builder{}
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range pathParameterPattern.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		pattern.WriteString("([^/]+)")
+		names = append(names, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	groups := regexp.MustCompile(pattern.String()).FindStringSubmatch(actual)
+	if groups == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(names))
+	for i, name := range names {
+		params[name] = groups[i+1]
+	}
+	return params, true
+}
+
+// parameterValue converts string values of the parameter to the JSON value of the schema type,
+// query arrays are taken from repeated parameters and other arrays are comma separated (form and simple styles).
+func parameterValue(schema interface{}, in string, values []string) interface{} {
+	s, _ := schema.(map[string]interface{})
+	if s["type"] == "array" {
+		if in != "query" {
+			values = strings.Split(values[0], ",")
+		}
+		out := make([]interface{}, len(values))
+		for i, v := range values {
+			out[i] = scalarValue(s["items"], v)
+		}
+		return out
+	}
+	return scalarValue(s, values[0])
+}
+
+// scalarValue keeps the value as string if it can't be converted, so the schema validation reports type mismatch.
+func scalarValue(schema interface{}, value string) interface{} {
+	s, _ := schema.(map[string]interface{})
+	switch s["type"] {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// mediaTypeSchema finds the schema by media type, wildcard media types like "application/*" are supported.
+func mediaTypeSchema(content map[string]interface{}, mediaType string) (interface{}, bool) {
+	if schema, ok := content[mediaType]; ok {
+		return schema, true
+	}
+	if i := strings.Index(mediaType, "/"); i > 0 {
+		if schema, ok := content[mediaType[:i]+"/*"]; ok {
+			return schema, true
+		}
+	}
+	schema, ok := content["*/*"]
+	return schema, ok
+}
+
+func mediaTypes(content map[string]interface{}) []string {
+	out := make([]string, 0, len(content))
+	for k := range content {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// String returns the operation description used in validation errors.
+func (o *Operation) String() string {
+	return fmt.Sprintf("%s (%s %s)", o.ID, o.Method, o.Path)
+}
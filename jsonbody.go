@@ -0,0 +1,219 @@
+package mock_server_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/jsonpath"
+)
+
+// EqualJSON verifies that the request JSON body is equal to the expected value, which is any value marshaled to JSON
+// (struct, map, json.RawMessage and so on). Order of object fields doesn't matter, order of array items does.
+// The failure contains all different fields, for example:
+// 		$.items[0].id: expected 1; actual 2
+// 		$.name: missing
+func (a *assertion) EqualJSON(expected interface{}) *assertion {
+	a.jsonChecks = append(a.jsonChecks, check{
+		name: "json body",
+		run: func(v *verification) error {
+			return v.assertJSON(expected, true)
+		},
+	})
+	return a
+}
+
+// ContainsJSON verifies that the request JSON body contains the subset: objects may have other fields,
+// arrays have to contain the same number of items in any order (the same way as MockServer MatchOnlyMatchingFields).
+func (a *assertion) ContainsJSON(subset interface{}) *assertion {
+	a.jsonChecks = append(a.jsonChecks, check{
+		name: "json body contains",
+		run: func(v *verification) error {
+			return v.assertJSON(subset, false)
+		},
+	})
+	return a
+}
+
+// JSONPathEquals verifies that the value selected from request JSON body by JSONPath is equal to the expected one,
+// for example JSONPathEquals("$.items[0].id", 1). If the path selects several values they are compared
+// as an array with the expected value. Invalid path leads the panic().
+func (a *assertion) JSONPathEquals(path string, expected interface{}) *assertion {
+	p, err := jsonpath.Compile(path)
+	if err != nil {
+		panic(fmt.Sprintf("invalid json path %s: %s", path, err))
+	}
+	a.jsonChecks = append(a.jsonChecks, check{
+		name: "json path " + path,
+		run: func(v *verification) error {
+			return v.assertJSONPath(path, p, expected)
+		},
+	})
+	return a
+}
+
+func (v *verification) jsonBody() (interface{}, error) {
+	data, _ := client.BodyBytes(v.body)
+	if len(data) == 0 {
+		return nil, errors.New("expected JSON body; actual no body")
+	}
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, errors.Wrapf(err, "request %s was invalid json", string(data))
+	}
+	return body, nil
+}
+
+func (v *verification) assertJSON(expected interface{}, strict bool) error {
+	actual, err := v.jsonBody()
+	if err != nil {
+		return err
+	}
+	e, err := normalizeJSON(expected)
+	if err != nil {
+		return err
+	}
+	return jsonMismatch("json body", jsonDifferences("$", e, actual, strict), e, actual)
+}
+
+func (v *verification) assertJSONPath(path string, p *jsonpath.Path, expected interface{}) error {
+	body, err := v.jsonBody()
+	if err != nil {
+		return err
+	}
+	e, err := normalizeJSON(expected)
+	if err != nil {
+		return err
+	}
+
+	nodes := p.Evaluate(body)
+	var actual interface{}
+	switch len(nodes) {
+	case 0:
+		return errors.Errorf("no value found by json path %s", path)
+	case 1:
+		actual = nodes[0]
+	default:
+		actual = nodes
+	}
+	return jsonMismatch("json path "+path, jsonDifferences(path, e, actual, true), e, actual)
+}
+
+func jsonMismatch(name string, differences []string, expected, actual interface{}) error {
+	if len(differences) == 0 {
+		return nil
+	}
+	return &valueMismatch{
+		message:  fmt.Sprintf("%s differs:\n\t%s", name, strings.Join(differences, "\n\t")),
+		expected: jsonIndent(expected),
+		actual:   jsonIndent(actual),
+	}
+}
+
+// normalizeJSON converts the value to the decoded JSON representation, so it can be compared with decoded body.
+func normalizeJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to marshal expected json %v", v)
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, errors.Wrapf(err, "unable to unmarshal expected json %s", string(data))
+	}
+	return out, nil
+}
+
+// jsonDifferences returns field level differences of decoded JSON values, the strict comparison requires the same
+// object fields and the same order of array items.
+func jsonDifferences(path string, expected, actual interface{}, strict bool) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object; actual %s", path, jsonString(actual))}
+		}
+		var out []string
+		for _, k := range sortedKeys(e) {
+			av, ok := a[k]
+			if !ok {
+				out = append(out, fmt.Sprintf("%s: missing", fieldPath(path, k)))
+				continue
+			}
+			out = append(out, jsonDifferences(fieldPath(path, k), e[k], av, strict)...)
+		}
+		if strict {
+			for _, k := range sortedKeys(a) {
+				if _, ok := e[k]; !ok {
+					out = append(out, fmt.Sprintf("%s: unexpected", fieldPath(path, k)))
+				}
+			}
+		}
+		return out
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array; actual %s", path, jsonString(actual))}
+		}
+		if len(a) != len(e) {
+			return []string{fmt.Sprintf("%s: expected %d items; actual %d", path, len(e), len(a))}
+		}
+		var out []string
+		if strict {
+			for i := range e {
+				out = append(out, jsonDifferences(path+"["+strconv.Itoa(i)+"]", e[i], a[i], strict)...)
+			}
+			return out
+		}
+		used := make([]bool, len(a))
+		for i, item := range e {
+			found := false
+			for j := range a {
+				if !used[j] && len(jsonDifferences(path, item, a[j], strict)) == 0 {
+					used[j], found = true, true
+					break
+				}
+			}
+			if !found {
+				out = append(out, fmt.Sprintf("%s[%d]: no item contains %s", path, i, jsonString(item)))
+			}
+		}
+		return out
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return []string{fmt.Sprintf("%s: expected %s; actual %s", path, jsonString(expected), jsonString(actual))}
+		}
+		return nil
+	}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func fieldPath(path, field string) string {
+	if identifier.MatchString(field) {
+		return path + "." + field
+	}
+	return path + "[" + strconv.Quote(field) + "]"
+}
+
+// jsonIndent returns indented JSON of the value, so the diff of expected and actual values is shown line by line.
+func jsonIndent(v interface{}) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,37 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestJSONAssertions(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	create := mock.On(http.MethodPost, "/pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated)).
+		NumCalls(2).
+		AssertionAtCall(0, msc.NewAssertion().
+			EqualJSON(pet{Name: "PoPo", Age: 3}).
+			JSONPathEquals("$.name", "PoPo"),
+		).
+		AssertionAtCall(1, msc.NewAssertion().
+			EqualJSON(json.RawMessage(`{"age": 4, "name": "JoJo"}`)).
+			ContainsJSON(map[string]interface{}{"name": "JoJo"}).
+			JSONPathEquals("$.age", 4),
+		)
+	require.NoError(t, mock.Setup(context.Background(), create))
+
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "PoPo", Age: 3}))
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "JoJo", Age: 4}))
+
+	require.NoError(t, mock.Verify(context.Background(), t))
+}
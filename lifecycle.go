@@ -0,0 +1,90 @@
+package mock_server_client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestOption configures MockServer created by NewForTest and NewInMemoryForTest.
+type TestOption func(*testOptions)
+
+type testOptions struct {
+	failOnUnexpectedRequests bool
+}
+
+// FailOnUnexpectedRequests fails the test when mock server app received requests which match no Expectation created
+// by On. The check considers all requests received by mock server app, so the test owns mock server app:
+// it's reset when the test starts and completes.
+func FailOnUnexpectedRequests() TestOption {
+	return func(o *testOptions) {
+		o.failOnUnexpectedRequests = true
+	}
+}
+
+// NewForTest creates a new MockServer client bound to the test, so there is no need to call Verify and Reset manually.
+// When the test completes all []Expectation created by On are verified, failures are reported to the test,
+// and the expectations are removed from mock server app:
+// 		func TestSomething(t *testing.T) {
+// 			mock := msc.NewForTest(t, msc.Config{Host: "localhost", Port: 1080}, msc.FailOnUnexpectedRequests())
+// 			mock.Setup(ctx, mock.On(http.MethodGet, "/some/endpoint").DefaultResponse(...).NumCalls(1))
+// 			...
+// 		}
+func NewForTest(t testing.TB, cfg Config, opts ...TestOption) *mockServer {
+	m := NewMockServer(cfg)
+	if c, ok := m.client.(interface{ Err() error }); ok && c.Err() != nil {
+		t.Fatalf("unable to create mock server client: %v", c.Err())
+	}
+	m.bind(t, opts...)
+	return m
+}
+
+// NewInMemoryForTest creates a new MockServer client backed by in-process mock server app bound to the test the same
+// way as NewForTest does. The returned httptest.Server is closed when the test completes.
+func NewInMemoryForTest(t testing.TB, opts ...TestOption) (*mockServer, *httptest.Server) {
+	m, server := NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+	m.bind(t, opts...)
+	return m, server
+}
+
+// bind registers cleanup verifying and removing expectations when the test completes.
+// Cleanup functions are called in reverse order, so the expectations are removed even if verification fails the test.
+func (m *mockServer) bind(t testing.TB, opts ...TestOption) {
+	o := testOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.failOnUnexpectedRequests {
+		if err := m.Reset(context.Background()); err != nil {
+			t.Fatalf("unable to reset mock server: %v", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		var err error
+		if o.failOnUnexpectedRequests {
+			err = m.Reset(context.Background())
+		} else {
+			for _, e := range m.expectations {
+				if err = m.Clear(context.Background(), e); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			t.Errorf("unable to clear mock server expectations: %v", err)
+		}
+	})
+	// failures and errors are reported to the test by the verification itself
+	t.Cleanup(func() {
+		_ = m.Verify(context.Background(), t)
+	})
+	if o.failOnUnexpectedRequests {
+		// the check is registered after Verify, so unexpected requests are reported first
+		t.Cleanup(func() {
+			_ = m.VerifyNoUnexpectedRequests(context.Background(), t)
+		})
+	}
+}
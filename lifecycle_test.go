@@ -0,0 +1,77 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+type pet struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func getPets(t testing.TB, url string) []pet {
+	rs, err := http.Get(url)
+	require.NoError(t, err)
+	defer rs.Body.Close()
+	require.Equal(t, http.StatusOK, rs.StatusCode)
+	var pets []pet
+	require.NoError(t, json.NewDecoder(rs.Body).Decode(&pets))
+	return pets
+}
+
+func TestBoundToTest(t *testing.T) {
+	mock, server := msc.NewInMemoryForTest(t, msc.FailOnUnexpectedRequests())
+
+	e := mock.On(http.MethodGet, "/pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody([]pet{{Name: "JoJo", Age: 2}})).
+		NumCalls(1)
+	require.NoError(t, mock.Setup(context.Background(), e))
+
+	assert.Equal(t, []pet{{Name: "JoJo", Age: 2}}, getPets(t, server.URL+"/pets"))
+}
+
+func TestBoundToTestFailure(t *testing.T) {
+	rt := &recordingT{TB: t}
+	// cleanup functions are called in reverse order, so the check runs after the verification of the bound test
+	t.Cleanup(func() {
+		require.Len(t, rt.errors, 2)
+		assert.Contains(t, rt.errors[0], "requests match no expectation:\n\tGET /dogs")
+		assert.Equal(t, "FAIL assertion:\nExpectation name [All pets]\nReason: expected num calls to /pets: 1; actual: 0", rt.errors[1])
+	})
+	mock, server := msc.NewInMemoryForTest(rt, msc.FailOnUnexpectedRequests())
+
+	e := mock.On(http.MethodGet, "/pets").Name("All pets").NumCalls(1)
+	require.NoError(t, mock.Setup(context.Background(), e))
+
+	assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/dogs"))
+}
+
+func TestForTestInvalidConfig(t *testing.T) {
+	rt := &recordingT{TB: t}
+	msc.NewForTest(rt, msc.Config{BaseURL: "ftp://localhost:1080"})
+	assert.Equal(t, "unable to create mock server client: invalid mock server client config: "+
+		"invalid base url ftp://localhost:1080: http(s) scheme and host are required", rt.fatal)
+}
+
+func BenchmarkGetAll(b *testing.B) {
+	mock, server := msc.NewInMemoryForTest(b)
+
+	e := mock.On(http.MethodGet, "/pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody([]pet{{Name: "JoJo", Age: 2}}))
+	require.NoError(b, mock.Setup(context.Background(), e))
+
+	for i := 0; i < b.N; i++ {
+		getPets(b, server.URL+"/pets")
+	}
+	b.StopTimer()
+
+	require.NoError(b, mock.VerifyTimes(context.Background(), b, e, msc.AtLeast(b.N)))
+}
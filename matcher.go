@@ -0,0 +1,120 @@
+package mock_server_client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Matcher checks recorded request inside MockServer.Verify, the error describes why the request doesn't match.
+// If the Matcher implements fmt.Stringer the string is used as the assertion name in reports.
+type Matcher interface {
+	Match(RecordedRequest) error
+}
+
+// MatcherFunc is a function which implements Matcher.
+type MatcherFunc func(RecordedRequest) error
+
+// Match calls the function.
+func (f MatcherFunc) Match(rq RecordedRequest) error {
+	return f(rq)
+}
+
+// Satisfies verifies the request by domain check, for example HMAC signature of the body:
+// 		msc.NewAssertion().Satisfies(func(rq msc.RecordedRequest) error {
+// 			mac := hmac.New(sha256.New, secret)
+// 			mac.Write(rq.Body)
+// 			if hex.EncodeToString(mac.Sum(nil)) != rq.Headers.Get("X-Signature") {
+// 				return errors.New("invalid signature")
+// 			}
+// 			return nil
+// 		})
+func (a *assertion) Satisfies(f func(RecordedRequest) error) *assertion {
+	a.matchers = append(a.matchers, check{
+		name: "satisfies",
+		run: func(v *verification) error {
+			return f(v.request)
+		},
+	})
+	return a
+}
+
+// Matches verifies the request by the Matcher, GomegaMatcher and Testify adapt matchers of other libraries.
+func (a *assertion) Matches(m Matcher) *assertion {
+	name := fmt.Sprintf("matcher %T", m)
+	if s, ok := m.(fmt.Stringer); ok {
+		name = s.String()
+	}
+	a.matchers = append(a.matchers, check{
+		name: name,
+		run: func(v *verification) error {
+			return m.Match(v.request)
+		},
+	})
+	return a
+}
+
+// GomegaMatcher has the same methods as gomega types.GomegaMatcher, so gomega matchers can be used
+// without the dependency.
+type GomegaMatcher interface {
+	Match(actual interface{}) (success bool, err error)
+	FailureMessage(actual interface{}) (message string)
+	NegatedFailureMessage(actual interface{}) (message string)
+}
+
+// Gomega adapts gomega matcher to Matcher, the matcher gets RecordedRequest, so gomega.WithTransform can be used
+// to check its fields:
+// 		msc.NewAssertion().Matches(msc.Gomega(
+// 			gomega.WithTransform(func(rq msc.RecordedRequest) string { return rq.Path }, gomega.HavePrefix("/pets/")),
+// 		))
+func Gomega(m GomegaMatcher) Matcher {
+	return gomegaAdapter{matcher: m}
+}
+
+type gomegaAdapter struct {
+	matcher GomegaMatcher
+}
+
+func (g gomegaAdapter) Match(rq RecordedRequest) error {
+	success, err := g.matcher.Match(rq)
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("%s", g.matcher.FailureMessage(rq))
+	}
+	return nil
+}
+
+func (g gomegaAdapter) String() string {
+	return fmt.Sprintf("gomega %T", g.matcher)
+}
+
+// TestingT has the same methods as testify assert.TestingT, so testify assertions can be used inside Testify matcher.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Testify adapts testify assertions to Matcher, all failed assertions are reported as the matcher failure:
+// 		msc.NewAssertion().Matches(msc.Testify(func(t msc.TestingT, rq msc.RecordedRequest) {
+// 			assert.Equal(t, "/pets/1", rq.Path)
+// 			assert.JSONEq(t, `{"name": "JoJo"}`, string(rq.Body))
+// 		}))
+func Testify(f func(t TestingT, rq RecordedRequest)) Matcher {
+	return MatcherFunc(func(rq RecordedRequest) error {
+		t := &collectingT{}
+		f(t, rq)
+		if len(t.errors) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s", strings.Join(t.errors, "\n"))
+	})
+}
+
+// collectingT collects failures of testify assertions.
+type collectingT struct {
+	errors []string
+}
+
+func (t *collectingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,84 @@
+package mock_server_client_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+// methodMatcher implements gomega matcher interface to show gomega matchers can be adapted.
+type methodMatcher struct {
+	method string
+}
+
+func haveMethod(method string) methodMatcher {
+	return methodMatcher{method: method}
+}
+
+func (m methodMatcher) Match(actual interface{}) (bool, error) {
+	rq, ok := actual.(msc.RecordedRequest)
+	if !ok {
+		return false, errors.New("methodMatcher expects msc.RecordedRequest")
+	}
+	return rq.Method == m.method, nil
+}
+
+func (m methodMatcher) FailureMessage(actual interface{}) string {
+	return "Expected request method to be " + m.method
+}
+
+func (m methodMatcher) NegatedFailureMessage(actual interface{}) string {
+	return "Expected request method not to be " + m.method
+}
+
+func TestCustomMatchers(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	secret := []byte("secret")
+	create := mock.On(http.MethodPost, "/pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated)).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().
+			Satisfies(func(rq msc.RecordedRequest) error {
+				mac := hmac.New(sha256.New, secret)
+				mac.Write(rq.Body)
+				if hex.EncodeToString(mac.Sum(nil)) != rq.Headers.Get("X-Signature") {
+					return errors.New("invalid signature")
+				}
+				return nil
+			}).
+			Matches(msc.Testify(func(t msc.TestingT, rq msc.RecordedRequest) {
+				assert.Equal(t, "/pets", rq.Path)
+				assert.JSONEq(t, `{"name": "JoJo", "age": 2}`, string(rq.Body))
+			})).
+			Matches(msc.Gomega(haveMethod(http.MethodPost))),
+		)
+	require.NoError(t, mock.Setup(context.Background(), create))
+
+	body := []byte(`{"name": "JoJo", "age": 2}`)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	rq, err := http.NewRequest(http.MethodPost, server.URL+"/pets", bytes.NewReader(body))
+	require.NoError(t, err)
+	rq.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	rs, err := http.DefaultClient.Do(rq)
+	require.NoError(t, err)
+	require.NoError(t, rs.Body.Close())
+
+	report, err := mock.Report(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Expectations[0].Calls[0].Assertions, 3)
+	assert.Equal(t, "gomega mock_server_client_test.methodMatcher", report.Expectations[0].Calls[0].Assertions[2].Name)
+	require.NoError(t, mock.Verify(context.Background(), t))
+}
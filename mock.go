@@ -2,12 +2,18 @@ package mock_server_client
 
 import (
 	"context"
-	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/inmemory"
 )
 
 var _ MockServer = (*mockServer)(nil)
@@ -20,12 +26,40 @@ type MockServer interface {
 	On(method, path string) *Expectation
 
 	Setup(context.Context, ...*Expectation) error
-
-	Verify(context.Context, *testing.T) error
-	VerifyExpectation(context.Context, *testing.T, *Expectation) error
+	SetupOpenAPI(ctx context.Context, specPathOrURL string, operations map[string]int) error
+	OnOpenAPI(specPathOrURL string, operations map[string]int) (map[string]*Expectation, error)
+
+	Verify(context.Context, testing.TB) error
+	VerifyExpectation(context.Context, testing.TB, *Expectation) error
+	VerifyTimes(context.Context, testing.TB, *Expectation, ...TimesOption) error
+	VerifyNever(context.Context, testing.TB, *Expectation) error
+	VerifySequence(context.Context, testing.TB, ...*Expectation) error
+	VerifyOrder(context.Context, testing.TB, ...*Expectation) error
+	VerifyEventually(ctx context.Context, t testing.TB, e *Expectation, timeout, interval time.Duration) error
+	VerifyNoUnexpectedRequests(context.Context, testing.TB) error
+
+	Check(context.Context) error
+	Report(context.Context) (*VerificationReport, error)
+	CheckExpectation(context.Context, *Expectation) error
+	CheckTimes(context.Context, *Expectation, ...TimesOption) error
+	CheckNever(context.Context, *Expectation) error
+	CheckSequence(context.Context, ...*Expectation) error
+	CheckOrder(context.Context, ...*Expectation) error
+	CheckEventually(ctx context.Context, e *Expectation, timeout, interval time.Duration) error
+	CheckNoUnexpectedRequests(context.Context) error
+	WaitForCalls(ctx context.Context, e *Expectation, n int) error
+
+	RecordedRequests(context.Context) ([]RecordedRequest, error)
+	RecordedRequestsAndResponses(context.Context) ([]RecordedRequestAndResponse, error)
+	ActiveExpectations(context.Context) ([]ActiveExpectation, error)
+	RecordedExpectations(context.Context) ([]RecordedExpectation, error)
+	LogMessages(context.Context) ([]string, error)
+	Subscribe(context.Context, *Expectation) <-chan RecordedRequest
 
 	Clear(context.Context, *Expectation) error
 	Reset(context.Context) error
+
+	Scope(testing.TB, ...ScopeOption) ScopedMockServer
 }
 
 // Config to communicate with mock server app.
@@ -33,27 +67,114 @@ type Config struct {
 	Host    string
 	Port    int
 	Verbose bool
+
+	// BaseURL of mock server app, for example "https://mockserver.example.com/some/prefix" when it's behind
+	// an ingress. It overrides Scheme, Host and Port.
+	BaseURL string
+	// Scheme is http or https, http by default.
+	Scheme string
+	// HTTPClient is used to call mock server app as is, so Transport, TLS, ProxyURL and Timeout are ignored.
+	// Callback websocket (see Expectation.RespondWith) is opened by dialer, proxy and TLS config of the client
+	// transport if it's *http.Transport, otherwise the websocket is connected directly without TLS client config.
+	HTTPClient *http.Client
+	// Transport is used instead of default transport, TLS and ProxyURL are ignored. The same limitation as for
+	// HTTPClient applies to callback websocket.
+	Transport http.RoundTripper
+	// TLS configures https connection, client certificate is used when mock server app requires mTLS.
+	TLS *TLSConfig
+	// ProxyURL of the proxy to reach mock server app, proxy from environment is used by default. Callback websocket
+	// is tunneled by CONNECT request, so only http proxy is supported for it.
+	ProxyURL string
+	// Timeout of each request to mock server app, no timeout by default.
+	Timeout time.Duration
+}
+
+// TLSConfig contains PEM encoded files and options of TLS connection to mock server app.
+type TLSConfig struct {
+	// CAFile is CA certificate to verify mock server app certificate, system CAs are used by default.
+	CAFile string
+	// CertFile and KeyFile are client certificate and key for mTLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the host name used to verify mock server app certificate.
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+func (cfg Config) clientConfig() client.Config {
+	out := client.Config{
+		BaseURL:    cfg.BaseURL,
+		Scheme:     cfg.Scheme,
+		Host:       cfg.Host,
+		Port:       cfg.Port,
+		Verbose:    cfg.Verbose,
+		HTTPClient: cfg.HTTPClient,
+		Transport:  cfg.Transport,
+		ProxyURL:   cfg.ProxyURL,
+		Timeout:    cfg.Timeout,
+	}
+	if cfg.TLS != nil {
+		tlsConfig := client.TLSConfig(*cfg.TLS)
+		out.TLS = &tlsConfig
+	}
+	return out
 }
 
 type mockServer struct {
 	client client.Client
 
 	expectations map[string]*Expectation
+	sequence     int
+	scope        *scope
 }
 
-// NewMockServer creates a new MockServer client
+// NewMockServer creates a new MockServer client.
+// Invalid Config (for example missing TLS files) is reported by the first call to mock server app.
 func NewMockServer(cfg Config) *mockServer {
 	return &mockServer{
-		client:       client.NewClient(cfg.Host, cfg.Port, cfg.Verbose),
+		client:       client.NewClient(cfg.clientConfig()),
 		expectations: map[string]*Expectation{},
 	}
 }
 
+// NewInMemoryMockServer creates a new MockServer client backed by in-process mock server app, so no running
+// mock server container is required. The returned httptest.Server serves mocked endpoints: its URL has to be used
+// by the system under test instead of mock server app address, and it has to be closed when testing is completed.
+func NewInMemoryMockServer() (*mockServer, *httptest.Server) {
+	s := inmemory.NewServer()
+	return &mockServer{
+		client:       s,
+		expectations: map[string]*Expectation{},
+	}, httptest.NewServer(s)
+}
+
+// NewInMemoryProxyMockServer creates a new MockServer client backed by in-process mock server app which works as
+// a proxy in front of the remote, for example "http://localhost:8081". Requests which don't match any Expectation
+// are forwarded to the remote and recorded, so they can be turned into Go code by MockServer.RecordedExpectations
+// and GenerateExpectationsCode.
+func NewInMemoryProxyMockServer(remote string) (*mockServer, *httptest.Server, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid remote %s", remote)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, nil, errors.Errorf("invalid remote %s: scheme and host are required", remote)
+	}
+	s := inmemory.NewProxy(u)
+	return &mockServer{
+		client:       s,
+		expectations: map[string]*Expectation{},
+	}, httptest.NewServer(s), nil
+}
+
 // On creates new Expectation when testing requires call to external endpoint by some HTTP method.
 // Expectation itself is builder, so you can set up it accordingly using corresponding approach:
 // expectation.Name("someName").NumCalls(10).Request(...)...
 func (m *mockServer) On(method, path string) *Expectation {
 	e := newExpectation(method, path)
+	m.sequence++
+	e.sequence = m.sequence
+	m.scope.apply(&e)
 	m.expectations[e.id] = &e
 	return &e
 }
@@ -62,6 +183,21 @@ func (m *mockServer) On(method, path string) *Expectation {
 // It sends expectation request to corresponding mock server app.
 func (m *mockServer) Setup(ctx context.Context, expectations ...*Expectation) error {
 	for _, expectation := range expectations {
+		if err := expectation.validate(); err != nil {
+			return errors.Wrapf(err, "invalid expectation %s", expectation)
+		}
+		if expectation.callbackClientID != "" {
+			// the expectation is set up again, so the callback of its previous setup is not used anymore
+			m.client.CloseCallback(expectation.callbackClientID)
+			expectation.callbackClientID = ""
+		}
+		if expectation.callback != nil {
+			id, err := m.client.Callback(ctx, callbackHandler(expectation.callback))
+			if err != nil {
+				return errors.Wrapf(err, "unable to register callback for expectation %s", expectation)
+			}
+			expectation.callbackClientID = id
+		}
 		for _, e := range expectation.build() {
 			err := m.client.Expectation(ctx, e)
 			if err != nil {
@@ -74,97 +210,138 @@ func (m *mockServer) Setup(ctx context.Context, expectations ...*Expectation) er
 }
 
 // Verify checks all []Expectation which were created by On method.
-func (m *mockServer) Verify(ctx context.Context, t *testing.T) error {
-	for _, expectation := range m.expectations {
-		err := m.verifyExpectation(ctx, t, expectation)
-		if err != nil {
-			return errors.Wrapf(err, "verification failed on expectation %s", expectation.id)
-		}
-	}
-	return nil
+// Test fails with all failed assertions of all expectations and *VerificationError is returned. If mock server app
+// can not be reached the test fails and the error is returned.
+func (m *mockServer) Verify(ctx context.Context, t testing.TB) error {
+	t.Helper()
+	return report(t, m.Check(ctx))
 }
 
 // VerifyExpectation checks the Expectation which was sent directly to the method.
-func (m *mockServer) VerifyExpectation(ctx context.Context, t *testing.T, expectation *Expectation) error {
-	return m.verifyExpectation(ctx, t, expectation)
+func (m *mockServer) VerifyExpectation(ctx context.Context, t testing.TB, expectation *Expectation) error {
+	t.Helper()
+	return report(t, m.CheckExpectation(ctx, expectation))
 }
 
-func (m *mockServer) verifyExpectation(ctx context.Context, t *testing.T, expectation *Expectation) error {
-	if expectation.numCalls == 0 && len(expectation.assertions) == 0 {
-		return nil
-	}
-	name := expectation.String()
+// VerifyTimes checks on mock server app that the Expectation request was received the number of times limited by
+// AtLeast and AtMost, at least once if no TimesOption is set. Test fails with mock server app mismatch description:
+// 		mock.VerifyTimes(ctx, t, e, msc.AtLeast(2), msc.AtMost(3))
+// The test fails and the error is returned if mock server app can not be reached.
+func (m *mockServer) VerifyTimes(ctx context.Context, t testing.TB, expectation *Expectation, opts ...TimesOption) error {
+	t.Helper()
+	return report(t, m.CheckTimes(ctx, expectation, opts...))
+}
+
+// VerifyNever checks on mock server app that the Expectation request was never received.
+func (m *mockServer) VerifyNever(ctx context.Context, t testing.TB, expectation *Expectation) error {
+	t.Helper()
+	return report(t, m.CheckNever(ctx, expectation))
+}
 
-	verifications, err := m.verifications(ctx, expectation)
+// VerifySequence checks on mock server app that requests of the []Expectation were received in the same order,
+// other requests can be received in between. Test fails with mock server app mismatch description.
+func (m *mockServer) VerifySequence(ctx context.Context, t testing.TB, expectations ...*Expectation) error {
+	t.Helper()
+	return report(t, m.CheckSequence(ctx, expectations...))
+}
+
+// Check checks all []Expectation which were created by On method the same way as Verify does, but returns
+// *VerificationError with all failed assertions instead of failing the test, so it can be used out of go tests.
+// Other errors mean mock server app can not be reached.
+func (m *mockServer) Check(ctx context.Context) error {
+	report, err := m.Report(ctx)
 	if err != nil {
-		t.Fatalf("FAIL assertion:\n"+
-			"Expectation name [%s]\n"+
-			"Reason: unable to verify expectation %s", name, expectation.id)
+		return err
 	}
+	return report.Error()
+}
 
-	if expectation.numCalls != 0 {
-		if len(verifications) != expectation.numCalls {
-			t.Fatalf("FAIL assertion:\n"+
-				"Expectation name [%s]\n"+
-				"Reason: expected num calls to %s: %d; actual: %d", name, expectation.request.path, expectation.numCalls, len(verifications))
-		}
+// CheckExpectation checks the Expectation the same way as VerifyExpectation does, but returns *VerificationError.
+func (m *mockServer) CheckExpectation(ctx context.Context, expectation *Expectation) error {
+	r, err := m.reportExpectation(ctx, expectation)
+	if err != nil {
+		return errors.Wrapf(err, "verification failed on expectation %s", expectation)
 	}
+	report := VerificationReport{Expectations: []ExpectationReport{r}}
+	return report.Error()
+}
 
-	fail := false
-
-	asserErr := func(id int, err error) {
-		if err != nil {
-			t.Errorf("FAIL assertion:\n"+
-				"Expectation name [%s]\n"+
-				"Assertion at call [%d]\n"+
-				"Reason: %s", name, id, err)
-			fail = true
-		}
+// CheckTimes checks the Expectation the same way as VerifyTimes does, but returns *VerificationError.
+func (m *mockServer) CheckTimes(ctx context.Context, expectation *Expectation, opts ...TimesOption) error {
+	times := TimesOptions{AtLeast: 0, AtMost: -1}
+	if len(opts) == 0 {
+		times.AtLeast = 1
+	}
+	for _, opt := range opts {
+		opt(&times)
 	}
 
-	for i, a := range expectation.assertions {
-		if i >= len(verifications) {
-			asserErr(i, fmt.Errorf("assertion index %d is out of bounds made calls %d", i, len(verifications)))
-			fail = true
-			continue
-		}
-		ver := verifications[i]
-
-		if a.requireBodyAssertion {
-			asserErr(i, ver.assertBody(a.bodyDecoder))
-		}
+	rq := clientHttpRequest(expectation.request)
+	err := m.client.Verify(ctx, client.Verify{
+		HTTPRequest: &rq,
+		Times:       &client.VerificationTimes{AtLeast: times.AtLeast, AtMost: times.AtMost},
+	})
+	return serverVerification(err, expectation.String())
+}
 
-		if a.requirePathAssertion {
-			asserErr(i, ver.assertPath(a.path))
-		}
+// CheckNever checks the Expectation the same way as VerifyNever does, but returns *VerificationError.
+func (m *mockServer) CheckNever(ctx context.Context, expectation *Expectation) error {
+	return m.CheckTimes(ctx, expectation, AtMost(0))
+}
 
-		for k, v := range a.queryParamRegexp {
-			asserErr(i, ver.assertQueryParameterRegexp(k, v))
-		}
-		for k, v := range a.queryParams {
-			asserErr(i, ver.assertQueryParameter(k, v))
-		}
-		for k, _ := range a.noQueryParams {
-			asserErr(i, ver.assertNoQueryParameter(k))
-		}
+// CheckSequence checks the []Expectation the same way as VerifySequence does, but returns *VerificationError.
+func (m *mockServer) CheckSequence(ctx context.Context, expectations ...*Expectation) error {
+	names := make([]string, len(expectations))
+	sequence := client.VerifySequence{HTTPRequests: make([]client.HTTPRequest, len(expectations))}
+	for i, e := range expectations {
+		names[i] = e.String()
+		sequence.HTTPRequests[i] = clientHttpRequest(e.request)
+	}
 
-		for k, v := range a.headers {
-			asserErr(i, ver.assertHeader(k, v))
-		}
-		for k, v := range a.headersRegexp {
-			asserErr(i, ver.assertHeaderRegexp(k, v))
-		}
-		for k, _ := range a.noHeaders {
-			asserErr(i, ver.assertNoHeader(k))
-		}
+	err := m.client.VerifySequence(ctx, sequence)
+	return serverVerification(err, strings.Join(names, ", "))
+}
 
+// serverVerification converts mock server app mismatch description to *VerificationError.
+func serverVerification(err error, name string) error {
+	if err == nil {
+		return nil
 	}
+	if reason, ok := errors.Cause(err).(client.VerificationError); ok {
+		return &VerificationError{Failures: []VerificationFailure{{Expectation: name, Call: NoCall, Reason: string(reason)}}}
+	}
+	return errors.Wrapf(err, "unable to verify expectation %s", name)
+}
 
-	if fail {
-		t.FailNow()
+// report fails the test with all failures of *VerificationError, other errors stop the test.
+// The error is returned in both cases.
+func report(t testing.TB, err error) error {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var verificationErr *VerificationError
+	if !errors.As(err, &verificationErr) {
+		t.Fatalf("FAIL assertion:\nReason: %v", err)
+		return err
+	}
+	for _, f := range verificationErr.Failures {
+		t.Errorf("%s", f)
 	}
+	t.FailNow()
+	return err
+}
 
-	return nil
+// sortedExpectations returns expectations in order they were created by On.
+func (m *mockServer) sortedExpectations() []*Expectation {
+	out := make([]*Expectation, 0, len(m.expectations))
+	for _, e := range m.expectations {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].sequence < out[j].sequence
+	})
+	return out
 }
 
 func (m *mockServer) verifications(ctx context.Context, expectation *Expectation) ([]verification, error) {
@@ -177,9 +354,11 @@ func (m *mockServer) verifications(ctx context.Context, expectation *Expectation
 	v := make([]verification, len(rs))
 	for i, r := range rs {
 		v[i] = verification{
-			path:        r.Path,
-			queryParams: r.QueryStringParameters,
+			request:     m.toRecordedRequest(r),
+			path:        m.scope.trimPath(r.Path),
+			queryParams: fromClientMultiValues(r.QueryStringParameters),
 			headers:     r.Headers,
+			cookies:     r.Cookies,
 			body:        r.Body,
 		}
 	}
@@ -187,17 +366,33 @@ func (m *mockServer) verifications(ctx context.Context, expectation *Expectation
 }
 
 // Clear removes the Expectation from mock server app and unregister it on MockServer client.
+// Callback websocket of the Expectation (see Expectation.RespondWith) is closed.
 func (m *mockServer) Clear(ctx context.Context, expectation *Expectation) error {
-	err := m.client.Clear(ctx, client.ClearRequest{ExpectationID: client.ExpectationID{ID: expectation.id}})
-	if err != nil {
-		return errors.Wrapf(err, "unable to remove expectation %s", expectation.id)
+	for _, id := range expectation.clientIDs {
+		err := m.client.Clear(ctx, client.ClearRequest{ExpectationID: client.ExpectationID{ID: id}})
+		if err != nil {
+			return errors.Wrapf(err, "unable to remove expectation %s", expectation.id)
+		}
+	}
+	if expectation.callbackClientID != "" {
+		m.client.CloseCallback(expectation.callbackClientID)
+		expectation.callbackClientID = ""
 	}
 	delete(m.expectations, expectation.id)
 	return nil
 }
 
 // Reset removes all []Expectation from mock server app and MockServer client.
+// ScopedMockServer removes only the scope expectations.
 func (m *mockServer) Reset(ctx context.Context) error {
+	if m.scope != nil {
+		for _, e := range m.expectations {
+			if err := m.Clear(ctx, e); err != nil {
+				return errors.Wrapf(err, "unable to reset scope %s", m.scope.id)
+			}
+		}
+		return nil
+	}
 	err := m.client.Reset(ctx)
 	if err != nil {
 		return errors.Wrap(err, "unable to reset mock server expectations")
@@ -0,0 +1,131 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+// recordingT records failures instead of failing the test, so failures of verification can be checked.
+type recordingT struct {
+	testing.TB
+
+	errors []string
+	fatal  string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.fatal = fmt.Sprintf(format, args...)
+}
+
+func (t *recordingT) FailNow() {}
+
+func TestVerifyUnreachableServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	mock := msc.NewMockServer(msc.Config{Host: "127.0.0.1", Port: port})
+	e := mock.On(http.MethodGet, "/pets").NumCalls(1)
+
+	rt := &recordingT{TB: t}
+	assert.Error(t, mock.Verify(context.Background(), rt))
+	assert.Contains(t, rt.fatal, "FAIL assertion:\nReason: ")
+	assert.Contains(t, rt.fatal, "connection refused")
+
+	rt = &recordingT{TB: t}
+	assert.Error(t, mock.VerifyOrder(context.Background(), rt, e))
+	assert.NotEmpty(t, rt.fatal)
+}
+
+func TestVerifyFailure(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	e := mock.On(http.MethodGet, "/pets").Name("All pets").NumCalls(1)
+	require.NoError(t, mock.Setup(context.Background(), e))
+
+	rt := &recordingT{TB: t}
+	err := mock.Verify(context.Background(), rt)
+	var verificationErr *msc.VerificationError
+	require.ErrorAs(t, err, &verificationErr)
+	require.Len(t, verificationErr.Failures, 1)
+	assert.Equal(t, "All pets", verificationErr.Failures[0].Expectation)
+	assert.Equal(t, []string{"FAIL assertion:\nExpectation name [All pets]\nReason: expected num calls to /pets: 1; actual: 0"}, rt.errors)
+}
+
+func TestServerSideVerification(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	create := mock.On(http.MethodPost, "/pets").
+		Name("Create pet").
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated))
+	read := mock.On(http.MethodGet, "/pets/{pet_id}").
+		Name("Read pet").
+		Request(msc.WithPathParameter("pet_id", "[0-9]+")).
+		DefaultResponse(
+			msc.WithStatusCode(http.StatusOK),
+			msc.WithResponseBody(pet{Name: "JoJo", Age: 2}),
+		)
+	list := mock.On(http.MethodGet, "/pets").
+		Name("List pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK))
+	require.NoError(t, mock.Setup(context.Background(), create, read, list))
+
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "JoJo", Age: 2}))
+	getPet(t, server.URL+"/pets/1")
+	getPet(t, server.URL+"/pets/1")
+
+	require.NoError(t, mock.VerifyTimes(context.Background(), t, create))
+	require.NoError(t, mock.VerifyTimes(context.Background(), t, read, msc.AtLeast(2), msc.AtMost(2)))
+	require.NoError(t, mock.VerifyNever(context.Background(), t, list))
+	require.NoError(t, mock.VerifySequence(context.Background(), t, create, read, read))
+}
+
+func TestCheck(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byID := mock.On(http.MethodGet, "/pets/{id}").
+		Name("Pet by id").
+		Request(msc.WithPathParameter("id", "[0-9]+")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "JoJo", Age: 2})).
+		NumCalls(2).
+		AssertionAtCall(0, msc.NewAssertion().WithPath("/pets/2")).
+		AssertionAtCall(1, msc.NewAssertion().WithPath("/pets/1"))
+	require.NoError(t, mock.Setup(context.Background(), byID))
+
+	getPet(t, server.URL+"/pets/1")
+
+	var verificationErr *msc.VerificationError
+	require.ErrorAs(t, mock.Check(context.Background()), &verificationErr)
+	require.Len(t, verificationErr.Failures, 3)
+	assert.Equal(t, msc.VerificationFailure{
+		Expectation: "Pet by id",
+		Call:        msc.NoCall,
+		Reason:      "expected num calls to /pets/{id}: 2; actual: 1",
+	}, verificationErr.Failures[0])
+	assert.Equal(t, 0, verificationErr.Failures[1].Call)
+	assert.Equal(t, "expected path /pets/2; actual path /pets/1", verificationErr.Failures[1].Reason)
+	assert.Equal(t, 1, verificationErr.Failures[2].Call)
+
+	getPet(t, server.URL+"/pets/2")
+	require.ErrorAs(t, mock.Check(context.Background()), &verificationErr)
+	require.Len(t, verificationErr.Failures, 2)
+	assert.NoError(t, mock.CheckTimes(context.Background(), byID, msc.AtLeast(2)))
+}
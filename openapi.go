@@ -0,0 +1,124 @@
+package mock_server_client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/openapi"
+)
+
+// SetupOpenAPI creates expectations on mock server app for operations of OpenAPI 3 document (JSON or YAML).
+// The operations map operationId to status code of the example response returned by the expectation,
+// all operations are created with the lowest 2xx response if the map is empty, 0 status selects the same response.
+// The document is loaded on the client side as well to send the status of each operation explicitly, so mock server
+// app and in-memory one return the same responses. The spec is URL or file path, local file is sent to mock server app
+// as it is, so:
+// 		mock.SetupOpenAPI(ctx, "testdata/petstore.yaml", map[string]int{"listPets": 200, "showPetById": 404})
+// The expectations are not available for Verify, use OnOpenAPI to create them on the client side.
+func (m *mockServer) SetupOpenAPI(ctx context.Context, specPathOrURL string, operations map[string]int) error {
+	if m.scope != nil {
+		// mock server app creates expectations by the document paths, so they are created on the client side in the scope
+		expectations, err := m.OnOpenAPI(specPathOrURL, operations)
+		if err != nil {
+			return errors.Wrapf(err, "unable to setup openapi expectations from %s", specPathOrURL)
+		}
+		for _, id := range sortedKeys(expectations) {
+			if err := m.Setup(ctx, expectations[id]); err != nil {
+				return errors.Wrapf(err, "unable to setup openapi expectations from %s", specPathOrURL)
+			}
+		}
+		return nil
+	}
+
+	spec := specPathOrURL
+	if info, err := os.Stat(specPathOrURL); err == nil && !info.IsDir() {
+		data, err := ioutil.ReadFile(specPathOrURL)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read openapi document %s", specPathOrURL)
+		}
+		spec = string(data)
+	}
+
+	doc, err := openapi.Load(spec)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load openapi document %s", specPathOrURL)
+	}
+	statuses, err := operationStatuses(doc, specPathOrURL, operations)
+	if err != nil {
+		return err
+	}
+
+	// mock server app selects the first response of the operation if the status is omitted,
+	// so the status is always sent to get the lowest 2xx one as in-memory mock server does
+	request := client.OpenAPIExpectation{SpecURLOrPayload: spec, OperationsAndResponses: map[string]string{}}
+	for _, id := range sortedKeys(statuses) {
+		request.OperationsAndResponses[id] = strconv.Itoa(statuses[id])
+	}
+	_, err = m.client.OpenAPIExpectation(ctx, request)
+	return errors.Wrapf(err, "unable to setup openapi expectations from %s", specPathOrURL)
+}
+
+// OnOpenAPI parses OpenAPI 3 document (JSON or YAML) from URL or file path and creates Expectation named by
+// operationId for each operation in the operations map, the Expectation returns example response with the status code.
+// All operations are created with the lowest 2xx response if the map is empty. The example is taken from the document
+// or generated from the response schema. The expectations can be tuned before MockServer.Setup:
+// 		expectations, err := mock.OnOpenAPI("testdata/petstore.yaml", map[string]int{"listPets": 200})
+// 		...
+// 		mock.Setup(ctx, expectations["listPets"].NumCalls(1))
+func (m *mockServer) OnOpenAPI(specPathOrURL string, operations map[string]int) (map[string]*Expectation, error) {
+	doc, err := openapi.Load(specPathOrURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load openapi document %s", specPathOrURL)
+	}
+
+	selected, err := operationStatuses(doc, specPathOrURL, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*Expectation, len(selected))
+	for _, id := range sortedKeys(selected) {
+		o, _ := doc.Operation(id)
+		rs, err := o.ExampleResponse(selected[id])
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create expectation for operation %s", id)
+		}
+		opts := []ResponseOption{WithStatusCode(rs.StatusCode)}
+		if rs.ContentType != "" {
+			opts = append(opts, WithResponseHeader("Content-Type", rs.ContentType))
+		}
+		if rs.Body != nil {
+			opts = append(opts, WithResponseBody(rs.Body))
+		}
+		out[id] = m.On(o.Method, o.Path).Name(id).DefaultResponse(opts...)
+	}
+	return out, nil
+}
+
+// operationStatuses maps the operations to status codes of their example responses, all operations of the document
+// are selected if the map is empty, 0 status is replaced by the lowest 2xx status of the operation.
+func operationStatuses(doc *openapi.Document, specPathOrURL string, operations map[string]int) (map[string]int, error) {
+	selected := map[string]int{}
+	if len(operations) == 0 {
+		for _, o := range doc.Operations() {
+			selected[o.ID] = o.DefaultStatus()
+		}
+		return selected, nil
+	}
+	for _, id := range sortedKeys(operations) {
+		o, ok := doc.Operation(id)
+		if !ok {
+			return nil, errors.Errorf("operation %s is not found in openapi document %s", id, specPathOrURL)
+		}
+		selected[id] = operations[id]
+		if selected[id] == 0 {
+			selected[id] = o.DefaultStatus()
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,130 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestOpenAPI(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+	require.NoError(t, mock.SetupOpenAPI(context.Background(), "testdata/pets.yaml", map[string]int{"listPets": http.StatusOK}))
+
+	pets := getPets(t, server.URL+"/pets")
+	require.Len(t, pets, 1)
+	assert.Equal(t, "JoJo", pets[0].Name)
+
+	expectations, err := mock.OnOpenAPI("testdata/pets.yaml", map[string]int{"showPetById": http.StatusNotFound, "createPet": 0})
+	require.NoError(t, err)
+	require.Len(t, expectations, 2)
+	require.NoError(t, mock.Setup(context.Background(),
+		expectations["showPetById"].NumCalls(1),
+		expectations["createPet"].NumCalls(1),
+	))
+
+	assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/pets/42"))
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "PoPo", Age: 3}))
+
+	require.NoError(t, mock.Verify(context.Background(), t))
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byID := mock.On(http.MethodGet, "/pets/{petId}").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "JoJo", Age: 2})).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().ConformsToOpenAPI("testdata/pets.yaml", "showPetById"))
+	create := mock.On(http.MethodPost, "/pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated)).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().ConformsToOpenAPI("testdata/pets.yaml", "createPet"))
+	require.NoError(t, mock.Setup(context.Background(), byID, create))
+
+	assert.Equal(t, pet{Name: "JoJo", Age: 2}, getPet(t, server.URL+"/pets/42"))
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "PoPo", Age: 3}))
+
+	require.NoError(t, mock.Verify(context.Background(), t))
+}
+
+func TestSetupOpenAPIStatuses(t *testing.T) {
+	for name, tc := range map[string]struct {
+		operations map[string]int
+		statuses   map[string]int
+	}{
+		"all operations": {
+			statuses: map[string]int{"/pets": http.StatusOK, "/pets/1": http.StatusOK},
+		},
+		"zero status selects default response": {
+			operations: map[string]int{"listPets": 0, "showPetById": http.StatusNotFound},
+			statuses:   map[string]int{"/pets": http.StatusOK, "/pets/1": http.StatusNotFound},
+		},
+		"selected operations only": {
+			operations: map[string]int{"showPetById": 0},
+			statuses:   map[string]int{"/pets": http.StatusNotFound, "/pets/1": http.StatusOK},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock, server := msc.NewInMemoryMockServer()
+			t.Cleanup(server.Close)
+			require.NoError(t, mock.SetupOpenAPI(context.Background(), "testdata/pets.yaml", tc.operations))
+
+			for path, status := range tc.statuses {
+				assert.Equal(t, status, get(t, server.URL+path), path)
+			}
+		})
+	}
+}
+
+func TestSetupOpenAPIRequest(t *testing.T) {
+	spec := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(spec.Close)
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		bodies <- string(data)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("[]"))
+	}))
+	t.Cleanup(server.Close)
+	mock := msc.NewMockServer(msc.Config{BaseURL: server.URL})
+
+	for name, tc := range map[string]struct {
+		operations map[string]int
+		statuses   string
+	}{
+		"all operations": {
+			statuses: `{"createPet": "201", "listPets": "200", "showPetById": "200"}`,
+		},
+		"zero status selects default response": {
+			operations: map[string]int{"showPetById": http.StatusNotFound, "listPets": 0},
+			statuses:   `{"listPets": "200", "showPetById": "404"}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, mock.SetupOpenAPI(context.Background(), spec.URL+"/pets.yaml", tc.operations))
+			// the status is always sent, mock server app would select the first response of the operation otherwise
+			assert.JSONEq(t, `{
+				"specUrlOrPayload": "`+spec.URL+`/pets.yaml",
+				"operationsAndResponses": `+tc.statuses+`
+			}`, <-bodies)
+		})
+	}
+}
+
+func TestSetupOpenAPIUnknownOperation(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+	err := mock.SetupOpenAPI(context.Background(), "testdata/pets.yaml", map[string]int{"deletePet": 0})
+	require.Error(t, err)
+	assert.Equal(t, "operation deletePet is not found in openapi document testdata/pets.yaml", err.Error())
+}
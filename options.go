@@ -1,9 +1,32 @@
 package mock_server_client
 
-import "time"
+import (
+	"encoding/base64"
+	"regexp"
+	"time"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
 
 type RequestOption func(*request)
 
+const (
+	// notPrefix negates matcher of method, path, key or value of headers and query parameters on mock server app.
+	notPrefix = "!"
+	// optionalPrefix makes header or query parameter optional, so it's checked only when it's sent.
+	optionalPrefix = "?"
+)
+
+// KeyMatchStyle defines how values of headers or query parameters are matched on mock server app.
+type KeyMatchStyle string
+
+const (
+	// SubSet requires each expected value matches at least one sent value, the default style.
+	SubSet KeyMatchStyle = KeyMatchStyle(client.SubSet)
+	// MatchingKey requires each sent value matches at least one expected value.
+	MatchingKey KeyMatchStyle = KeyMatchStyle(client.MatchingKey)
+)
+
 // WithPathParameter sets required request path parameter that has to be checked on mock
 // server app to return corresponding response.
 func WithPathParameter(key, value string) RequestOption {
@@ -16,24 +39,126 @@ func WithPathParameter(key, value string) RequestOption {
 }
 
 // WithQueryParameter sets required query parameter that has to be checked on mock
-// server app to return corresponding response.
+// server app to return corresponding response. Repeated key overwrites the value,
+// use WithQueryParameterValues to require several values of the parameter.
 func WithQueryParameter(key, value string) RequestOption {
 	return func(r *request) {
 		if r.queryParams == nil {
-			r.queryParams = map[string]string{}
+			r.queryParams = map[string][]string{}
 		}
-		r.queryParams[key] = value
+		r.queryParams[key] = []string{value}
+	}
+}
+
+// WithQueryParameterValues adds required query parameter values that have to be checked on mock
+// server app to return corresponding response, for example ?tag=a&tag=b. Repeated key adds values to the key.
+func WithQueryParameterValues(key string, values ...string) RequestOption {
+	return func(r *request) {
+		if r.queryParams == nil {
+			r.queryParams = map[string][]string{}
+		}
+		r.queryParams[key] = append(r.queryParams[key], values...)
+	}
+}
+
+// WithOptionalQueryParameter adds query parameter values that are checked only when the parameter is sent.
+func WithOptionalQueryParameter(key string, values ...string) RequestOption {
+	return WithQueryParameterValues(optionalPrefix+key, values...)
+}
+
+// WithQueryParameterMatchStyle sets how values of query parameters are matched, SubSet is used by default.
+func WithQueryParameterMatchStyle(style KeyMatchStyle) RequestOption {
+	return func(r *request) {
+		r.queryMatchStyle = client.KeyMatchStyle(style)
 	}
 }
 
 // WithRequestHeader sets required header parameter that has to be checked on mock
-// server app to return corresponding response.
+// server app to return corresponding response. Repeated key overwrites the value,
+// use WithRequestHeaderValues to require several values of the header.
 func WithRequestHeader(key, value string) RequestOption {
 	return func(r *request) {
 		if r.headers == nil {
-			r.headers = map[string]string{}
+			r.headers = map[string][]string{}
 		}
-		r.headers[key] = value
+		r.headers[key] = []string{value}
+	}
+}
+
+// WithRequestHeaderValues adds required header values that have to be checked on mock
+// server app to return corresponding response. Repeated key adds values to the key.
+func WithRequestHeaderValues(key string, values ...string) RequestOption {
+	return func(r *request) {
+		if r.headers == nil {
+			r.headers = map[string][]string{}
+		}
+		r.headers[key] = append(r.headers[key], values...)
+	}
+}
+
+// WithOptionalRequestHeader adds header values that are checked only when the header is sent.
+func WithOptionalRequestHeader(key string, values ...string) RequestOption {
+	return WithRequestHeaderValues(optionalPrefix+key, values...)
+}
+
+// WithRequestHeaderMatchStyle sets how values of headers are matched, SubSet is used by default.
+func WithRequestHeaderMatchStyle(style KeyMatchStyle) RequestOption {
+	return func(r *request) {
+		r.headerMatchStyle = client.KeyMatchStyle(style)
+	}
+}
+
+// WithRequestCookie sets required cookie with exact value that has to be checked on mock
+// server app to return corresponding response.
+func WithRequestCookie(name, value string) RequestOption {
+	return WithRequestCookieRegexp(name, regexp.QuoteMeta(value))
+}
+
+// WithRequestCookieRegexp sets required cookie which value has to match the regular expression on mock
+// server app to return corresponding response.
+func WithRequestCookieRegexp(name, value string) RequestOption {
+	return func(r *request) {
+		if r.cookies == nil {
+			r.cookies = map[string]string{}
+		}
+		r.cookies[name] = value
+	}
+}
+
+// WithNotQueryParameter sets query parameter which has to be sent with any value except the value.
+func WithNotQueryParameter(key, value string) RequestOption {
+	return WithQueryParameter(key, notPrefix+value)
+}
+
+// WithoutQueryParameter sets query parameter which must not be sent.
+func WithoutQueryParameter(key string) RequestOption {
+	return WithQueryParameter(notPrefix+key, ".*")
+}
+
+// WithNotRequestHeader sets header which has to be sent with any value except the value.
+func WithNotRequestHeader(key, value string) RequestOption {
+	return WithRequestHeader(key, notPrefix+value)
+}
+
+// WithoutRequestHeader sets header which must not be sent.
+func WithoutRequestHeader(key string) RequestOption {
+	return WithRequestHeader(notPrefix+key, ".*")
+}
+
+// NotPath negates the path of Expectation, so any request except the requests to the path are matched.
+// The path of ScopedMockServer identified by path prefix can not be negated, because the prefix is a part of
+// the path, MockServer.Setup returns error. The scope identified by WithScopeHeader negates only the path.
+func NotPath() RequestOption {
+	return func(r *request) {
+		r.notPath = true
+	}
+}
+
+// NotBody negates the body set by WithRequestBody or any typed body option, so any request except the requests
+// with the body are matched.
+func NotBody() RequestOption {
+	return func(r *request) {
+		r.notBody = true
 	}
 }
 
@@ -45,6 +170,125 @@ func WithRequestBody(body interface{}) RequestOption {
 	}
 }
 
+// JSONMatchType defines how strict JSON body is compared with the request body on mock server app.
+type JSONMatchType string
+
+const (
+	// MatchOnlyFields allows extra fields in request body and any order of array items.
+	MatchOnlyFields JSONMatchType = JSONMatchType(client.OnlyMatchingFields)
+	// MatchStrict requires exactly the same fields and the same order of array items.
+	MatchStrict JSONMatchType = JSONMatchType(client.Strict)
+)
+
+// WithJSONBody sets required JSON body, the value is marshaled to JSON and compared with request body
+// according to the matchType.
+func WithJSONBody(v interface{}, matchType JSONMatchType) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type:      client.JSONBody,
+			JSON:      v,
+			MatchType: client.MatchType(matchType),
+		}
+	}
+}
+
+// WithJSONSchemaBody sets JSON schema the request body has to be valid against.
+// The schema can be raw JSON string or any value which is marshaled to JSON schema.
+func WithJSONSchemaBody(schema interface{}) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type:       client.JSONSchemaBody,
+			JSONSchema: schema,
+		}
+	}
+}
+
+// WithJSONPathBody sets JSONPath expression which has to select at least one node of the request body,
+// for example: $.items[?(@.price > 10)]
+func WithJSONPathBody(path string) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type:     client.JSONPathBody,
+			JSONPath: path,
+		}
+	}
+}
+
+// WithXPathBody sets XPath expression which has to select at least one node of the request body,
+// for example: /bookstore/book[price>30]/price
+func WithXPathBody(xpath string) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type:  client.XPathBody,
+			XPath: xpath,
+		}
+	}
+}
+
+// WithXMLBody sets required XML body, it's compared with request body ignoring formatting.
+func WithXMLBody(xml string) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type: client.XMLBody,
+			XML:  xml,
+		}
+	}
+}
+
+// WithRegexBody sets regular expression the whole request body has to match.
+func WithRegexBody(regex string) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type:  client.RegexBody,
+			Regex: regex,
+		}
+	}
+}
+
+// WithSubstringBody sets string the request body has to contain.
+func WithSubstringBody(s string) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type:      client.StringBody,
+			String:    s,
+			SubString: true,
+		}
+	}
+}
+
+// WithBinaryBody sets exact bytes of required request body.
+func WithBinaryBody(b []byte) RequestOption {
+	return func(r *request) {
+		r.body = client.Body{
+			Type:        client.BinaryBody,
+			Base64Bytes: base64.StdEncoding.EncodeToString(b),
+		}
+	}
+}
+
+// TimesOptions limits number of times the request has to be received by mock server app.
+type TimesOptions struct {
+	AtLeast int
+	// AtMost is not limited if it's negative
+	AtMost int
+}
+
+type TimesOption func(*TimesOptions)
+
+// AtLeast sets minimal number of times the request has to be received by mock server app.
+func AtLeast(n int) TimesOption {
+	return func(t *TimesOptions) {
+		t.AtLeast = n
+	}
+}
+
+// AtMost sets maximal number of times the request can be received by mock server app.
+func AtMost(n int) TimesOption {
+	return func(t *TimesOptions) {
+		t.AtMost = n
+	}
+}
+
 type ResponseOption func(*response)
 
 // WithResponseHeader sets response header to be returned within corresponding HTTP response from mock server app.
@@ -57,6 +301,17 @@ func WithResponseHeader(key, value string) ResponseOption {
 	}
 }
 
+// WithResponseCookie sets response cookie to be returned within Set-Cookie header of corresponding HTTP response
+// from mock server app.
+func WithResponseCookie(name, value string) ResponseOption {
+	return func(r *response) {
+		if r.cookies == nil {
+			r.cookies = map[string]string{}
+		}
+		r.cookies[name] = value
+	}
+}
+
 // WithResponseBody sets response body to be returned within corresponding HTTP response from mock server app.
 func WithResponseBody(body interface{}) ResponseOption {
 	return func(r *response) {
@@ -98,3 +353,91 @@ func WithErrorBytes(b []byte) ResponseOption {
 		r.errorBytes = b
 	}
 }
+
+// WithVelocityTemplate sets Velocity template the response is rendered by on mock server app.
+// The template has to produce JSON of the response, for example:
+// 		{ "statusCode": 200, "body": { "id": "$!request.pathParameters['id'][0]" } }
+func WithVelocityTemplate(template string) ResponseOption {
+	return func(r *response) {
+		r.template = template
+		r.templateType = client.VELOCITY
+	}
+}
+
+// WithMustacheTemplate sets Mustache template the response is rendered by on mock server app.
+// The template has to produce JSON of the response, for example:
+// 		{ "statusCode": 200, "body": { "id": "{{ request.pathParameters.id.0 }}" } }
+// The template can be checked locally by RenderMustacheTemplate before MockServer.Setup.
+func WithMustacheTemplate(template string) ResponseOption {
+	return func(r *response) {
+		r.template = template
+		r.templateType = client.MUSTACHE
+	}
+}
+
+// WithJavaScriptTemplate sets JavaScript template the response is rendered by on mock server app.
+// The template has to return the response object, for example:
+// 		return { statusCode: 200, body: { id: request.pathParameters['id'][0] } };
+func WithJavaScriptTemplate(template string) ResponseOption {
+	return func(r *response) {
+		r.template = template
+		r.templateType = client.JAVASCRIPT
+	}
+}
+
+type ForwardOption func(*forward)
+
+// WithForwardHost sets host the request is forwarded to by mock server app.
+func WithForwardHost(host string) ForwardOption {
+	return func(f *forward) {
+		f.host = host
+	}
+}
+
+// WithForwardPort sets port the request is forwarded to by mock server app.
+func WithForwardPort(port int) ForwardOption {
+	return func(f *forward) {
+		f.port = port
+	}
+}
+
+// WithForwardScheme sets scheme (http or https) the request is forwarded with by mock server app.
+func WithForwardScheme(scheme string) ForwardOption {
+	return func(f *forward) {
+		f.scheme = scheme
+	}
+}
+
+// WithForwardPath rewrites path of the forwarded request.
+func WithForwardPath(path string) ForwardOption {
+	return func(f *forward) {
+		f.path = path
+		f.override = true
+	}
+}
+
+// WithForwardHeader rewrites header of the forwarded request.
+func WithForwardHeader(key, value string) ForwardOption {
+	return func(f *forward) {
+		if f.headers == nil {
+			f.headers = map[string]string{}
+		}
+		f.headers[key] = value
+		f.override = true
+	}
+}
+
+// WithForwardBody rewrites body of the forwarded request.
+func WithForwardBody(body interface{}) ForwardOption {
+	return func(f *forward) {
+		f.body = body
+		f.override = true
+	}
+}
+
+// WithForwardDelay sets delay before the request is forwarded by mock server app.
+func WithForwardDelay(d time.Duration) ForwardOption {
+	return func(f *forward) {
+		f.delay = &d
+	}
+}
@@ -0,0 +1,52 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestBodyMatchers(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byName := mock.On(http.MethodPost, "/pets").
+		Name("Pet by name").
+		Request(msc.WithJSONBody(map[string]interface{}{"name": "JoJo"}, msc.MatchOnlyFields)).
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated)).
+		NumCalls(1)
+	bySchema := mock.On(http.MethodPost, "/pets").
+		Name("Pet by schema").
+		Request(msc.WithJSONSchemaBody(`{
+			"type": "object",
+			"required": ["name", "age"],
+			"properties": {"name": {"type": "string"}, "age": {"type": "integer", "maximum": 30}}
+		}`)).
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated)).
+		NumCalls(2)
+	tooOld := mock.On(http.MethodPost, "/pets").
+		Name("Too old pet").
+		Request(msc.WithJSONPathBody("$[?(@.age > 100)]")).
+		DefaultResponse(msc.WithStatusCode(http.StatusBadRequest)).
+		NumCalls(1)
+	require.NoError(t, mock.Setup(context.Background(), byName, bySchema, tooOld))
+
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "JoJo", Age: 2}))
+	assert.Equal(t, http.StatusCreated, postPet(t, server.URL+"/pets", pet{Name: "PoPo", Age: 5}))
+	assert.Equal(t, http.StatusBadRequest, postPet(t, server.URL+"/pets", pet{Name: "LoLo", Age: 150}))
+
+	require.NoError(t, mock.Verify(context.Background(), t))
+}
+
+func TestTimesOptions(t *testing.T) {
+	times := msc.TimesOptions{AtMost: -1}
+	for _, opt := range []msc.TimesOption{msc.AtLeast(2), msc.AtMost(3)} {
+		opt(&times)
+	}
+	assert.Equal(t, msc.TimesOptions{AtLeast: 2, AtMost: 3}, times)
+}
@@ -0,0 +1,145 @@
+package mock_server_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+// VerifyOrder checks that each of the []Expectation was called and its first call was received before the first
+// call of the next one, for example token endpoint is called before data endpoint:
+// 		mock.VerifyOrder(ctx, t, token, data)
+// Unlike VerifySequence the expectations can be called any number of times, so retries and token refreshes don't
+// fail the test. Test fails with the timeline of calls matched by the expectations:
+// 		timeline:
+// 			[0] 12:01:02.003 GET /data (data)
+// 			[1] 12:01:02.005 POST /token (token)
+// The test fails and the error is returned if mock server app can not be reached.
+func (m *mockServer) VerifyOrder(ctx context.Context, t testing.TB, expectations ...*Expectation) error {
+	t.Helper()
+	return report(t, m.CheckOrder(ctx, expectations...))
+}
+
+// CheckOrder checks the []Expectation the same way as VerifyOrder does, but returns *VerificationError.
+func (m *mockServer) CheckOrder(ctx context.Context, expectations ...*Expectation) error {
+	names := make([]string, len(expectations))
+	for i, e := range expectations {
+		names[i] = e.String()
+	}
+
+	calls, err := m.timeline(ctx, expectations)
+	if err != nil {
+		return errors.Wrapf(err, "unable to verify order of expectations %s", strings.Join(names, ", "))
+	}
+
+	first := make([]int, len(expectations))
+	for i := range first {
+		first[i] = -1
+	}
+	for i, c := range calls {
+		for _, e := range c.expectations {
+			if first[e] == -1 {
+				first[e] = i
+			}
+		}
+	}
+
+	var reasons []string
+	for i := range expectations {
+		switch {
+		case first[i] == -1:
+			reasons = append(reasons, fmt.Sprintf("%s was never called", names[i]))
+		case i > 0 && first[i-1] != -1 && first[i] <= first[i-1]:
+			reasons = append(reasons, fmt.Sprintf("first call of %s [%d] was received before first call of %s [%d]",
+				names[i], first[i], names[i-1], first[i-1]))
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(calls))
+	for i, c := range calls {
+		matched := make([]string, len(c.expectations))
+		for j, e := range c.expectations {
+			matched[j] = names[e]
+		}
+		timestamp := "--:--:--.---"
+		if !c.Timestamp.IsZero() {
+			timestamp = c.Timestamp.Format("15:04:05.000")
+		}
+		lines[i] = fmt.Sprintf("\t[%d] %s %s %s (%s)", i, timestamp, c.Request.Method, c.Request.Path,
+			strings.Join(matched, ", "))
+	}
+	return &VerificationError{Failures: []VerificationFailure{{
+		Expectation: strings.Join(names, ", "),
+		Call:        NoCall,
+		Reason: fmt.Sprintf("expected order: %s\n%s\ntimeline:\n%s",
+			strings.Join(names, ", "), strings.Join(reasons, "\n"), strings.Join(lines, "\n")),
+	}}}
+}
+
+// timelineCall is a call received by mock server app with indexes of the expectations it matched.
+type timelineCall struct {
+	RecordedRequestAndResponse
+	expectations []int
+}
+
+// timeline returns calls matched by the []Expectation in order they were received by mock server app.
+// The order is taken from all recorded requests, the calls matched by the expectation are found there
+// by the request and its timestamp.
+func (m *mockServer) timeline(ctx context.Context, expectations []*Expectation) ([]timelineCall, error) {
+	all, err := m.client.RetrieveRequestResponses(ctx, m.scope.matcher())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get recorded requests")
+	}
+	positions := map[string][]int{}
+	for i, r := range all {
+		key := recordedExchangeKey(r)
+		positions[key] = append(positions[key], i)
+	}
+
+	matched := make([][]int, len(all))
+	for i, e := range expectations {
+		rs, err := m.client.RetrieveRequestResponses(ctx, client.RetrieveRequest(clientHttpRequest(e.request)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get recorded requests of expectation %s", e)
+		}
+		used := map[string]int{}
+		for _, r := range rs {
+			key := recordedExchangeKey(r)
+			if used[key] >= len(positions[key]) {
+				continue
+			}
+			position := positions[key][used[key]]
+			used[key]++
+			matched[position] = append(matched[position], i)
+		}
+	}
+
+	var out []timelineCall
+	for i, r := range all {
+		if len(matched[i]) == 0 {
+			continue
+		}
+		c := timelineCall{expectations: matched[i]}
+		if r.HTTPRequest != nil {
+			c.Request = m.toRecordedRequest(*r.HTTPRequest)
+		}
+		c.Timestamp = parseTimestamp(r.Timestamp)
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// recordedExchangeKey identifies the recorded request, the same request received twice differs by the timestamp.
+func recordedExchangeKey(r client.HTTPRequestAndResponse) string {
+	data, _ := json.Marshal([]interface{}{r.HTTPRequest, r.Timestamp})
+	return string(data)
+}
@@ -0,0 +1,41 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestOrder(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	create := mock.On(http.MethodPost, "/pets").
+		Name("Create pet").
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated))
+	all := mock.On(http.MethodGet, "/pets").
+		Name("All pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody([]pet{{Name: "JoJo", Age: 2}}))
+	require.NoError(t, mock.Setup(context.Background(), create, all))
+
+	postPet(t, server.URL+"/pets", pet{Name: "JoJo", Age: 2})
+	getPets(t, server.URL+"/pets")
+	getPets(t, server.URL+"/pets")
+	postPet(t, server.URL+"/pets", pet{Name: "PoPo", Age: 3})
+
+	assert.NoError(t, mock.VerifyOrder(context.Background(), t, create, all))
+
+	var verificationErr *msc.VerificationError
+	require.ErrorAs(t, mock.CheckOrder(context.Background(), all, create), &verificationErr)
+	require.Len(t, verificationErr.Failures, 1)
+	reason := verificationErr.Failures[0].Reason
+	assert.Contains(t, reason, "first call of Create pet [0] was received before first call of All pets [1]")
+	assert.Contains(t, reason, "POST /pets (Create pet)")
+	assert.Contains(t, reason, "[2] ")
+	assert.Contains(t, reason, "GET /pets (All pets)")
+}
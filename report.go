@@ -0,0 +1,329 @@
+package mock_server_client
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/diff"
+)
+
+// VerificationReport is the result of verification of []Expectation created by On. It lists each expectation,
+// each recorded call and each assertion with its result, so it can be exported by JSON or JUnit for CI:
+// 		report, err := mock.Report(ctx)
+// 		...
+// 		data, err := report.JUnit("payments dependencies")
+// 		...
+// 		ioutil.WriteFile("mock-server-report.xml", data, 0644)
+type VerificationReport struct {
+	Expectations []ExpectationReport `json:"expectations"`
+}
+
+// ExpectationReport is the verification result of the Expectation, Failures are not related to a particular call,
+// for example wrong number of calls.
+type ExpectationReport struct {
+	Name          string       `json:"name"`
+	Method        string       `json:"method"`
+	Path          string       `json:"path"`
+	ExpectedCalls int          `json:"expectedCalls,omitempty"`
+	Passed        bool         `json:"passed"`
+	Failures      []string     `json:"failures,omitempty"`
+	Calls         []CallReport `json:"calls"`
+}
+
+// CallReport is a recorded call of the Expectation with results of assertions set by AssertionAtCall.
+// Missing calls are reported for assertions at calls which were not made.
+type CallReport struct {
+	Index      int               `json:"index"`
+	Missing    bool              `json:"missing,omitempty"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Body       string            `json:"body,omitempty"`
+	Passed     bool              `json:"passed"`
+	Assertions []AssertionReport `json:"assertions,omitempty"`
+}
+
+// AssertionReport is the result of a single assertion, Diff is unified diff of expected and actual values
+// if the assertion compares them.
+type AssertionReport struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+
+	expected string
+	actual   string
+}
+
+// Report verifies all []Expectation which were created by On method and returns the report of all calls and
+// assertions, the error is returned only if mock server app can not be reached.
+func (m *mockServer) Report(ctx context.Context) (*VerificationReport, error) {
+	report := &VerificationReport{}
+	for _, expectation := range m.sortedExpectations() {
+		r, err := m.reportExpectation(ctx, expectation)
+		if err != nil {
+			return nil, errors.Wrapf(err, "verification failed on expectation %s", expectation)
+		}
+		report.Expectations = append(report.Expectations, r)
+	}
+	return report, nil
+}
+
+func (m *mockServer) reportExpectation(ctx context.Context, expectation *Expectation) (ExpectationReport, error) {
+	name := expectation.String()
+	verifications, err := m.verifications(ctx, expectation)
+	if err != nil {
+		return ExpectationReport{}, errors.Wrapf(err, "unable to verify expectation %s", name)
+	}
+
+	r := ExpectationReport{
+		Name:          name,
+		Method:        expectation.request.method,
+		Path:          expectation.request.path,
+		ExpectedCalls: expectation.numCalls,
+		Calls:         make([]CallReport, len(verifications)),
+	}
+	if expectation.numCalls != 0 && len(verifications) != expectation.numCalls {
+		r.Failures = append(r.Failures, fmt.Sprintf("expected num calls to %s: %d; actual: %d",
+			expectation.request.path, expectation.numCalls, len(verifications)))
+	}
+
+	r.Passed = len(r.Failures) == 0
+	for i := range verifications {
+		call := reportCall(i, &verifications[i], expectation.callAssertions(i, len(verifications)))
+		r.Passed = r.Passed && call.Passed
+		r.Calls[i] = call
+	}
+
+	if len(verifications) == 0 && len(expectation.lastCallAssertions) > 0 {
+		r.Failures = append(r.Failures, "no calls to check assertion on last call")
+	}
+	for _, a := range expectation.anyCallAssertions {
+		if failure := anyCall(a, verifications); failure != "" {
+			r.Failures = append(r.Failures, failure)
+		}
+	}
+	r.Passed = r.Passed && len(r.Failures) == 0
+
+	missing := make([]int, 0, len(expectation.assertions))
+	for i := range expectation.assertions {
+		if i >= len(verifications) {
+			missing = append(missing, i)
+		}
+	}
+	sort.Ints(missing)
+	for _, i := range missing {
+		r.Passed = false
+		r.Calls = append(r.Calls, CallReport{
+			Index:   i,
+			Missing: true,
+			Assertions: []AssertionReport{{
+				Name:    "call",
+				Message: fmt.Sprintf("assertion index %d is out of bounds made calls %d", i, len(verifications)),
+			}},
+		})
+	}
+	return r, nil
+}
+
+// anyCall returns the failure if no call satisfies all checks of the assertion.
+func anyCall(a *assertion, verifications []verification) string {
+	if len(verifications) == 0 {
+		return "no calls to check assertion on any call"
+	}
+	reasons := make([]string, len(verifications))
+	for i := range verifications {
+		var failed []string
+		for _, c := range a.checks() {
+			if err := c.run(&verifications[i]); err != nil {
+				failed = append(failed, c.name+": "+err.Error())
+			}
+		}
+		if len(failed) == 0 {
+			return ""
+		}
+		reasons[i] = fmt.Sprintf("call [%d]: %s", i, strings.Join(failed, "; "))
+	}
+	return "no call satisfies assertion on any call:\n\t" + strings.Join(reasons, "\n\t")
+}
+
+func reportCall(index int, v *verification, assertions []namedAssertion) CallReport {
+	call := CallReport{
+		Index:  index,
+		Method: v.request.Method,
+		Path:   v.request.Path,
+		Passed: true,
+	}
+	if utf8.Valid(v.request.Body) {
+		call.Body = string(v.request.Body)
+	}
+
+	var checks []check
+	for _, a := range assertions {
+		for _, c := range a.assertion.checks() {
+			checks = append(checks, check{name: a.prefix + c.name, run: c.run})
+		}
+	}
+	for _, c := range checks {
+		result := AssertionReport{Name: c.name, Passed: true}
+		if err := c.run(v); err != nil {
+			result.Passed = false
+			result.Message = err.Error()
+			var mismatch *valueMismatch
+			if errors.As(err, &mismatch) {
+				result.expected, result.actual = mismatch.expected, mismatch.actual
+				result.Diff = diff.Unified(mismatch.expected, mismatch.actual)
+			}
+			call.Passed = false
+		}
+		call.Assertions = append(call.Assertions, result)
+	}
+	return call
+}
+
+// Passed checks if all expectations passed verification.
+func (r *VerificationReport) Passed() bool {
+	for _, e := range r.Expectations {
+		if !e.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Error returns *VerificationError with all failures of the report, nil if the report passed.
+func (r *VerificationReport) Error() error {
+	failures := &VerificationError{}
+	for _, e := range r.Expectations {
+		for _, f := range e.Failures {
+			failures.Failures = append(failures.Failures, VerificationFailure{Expectation: e.Name, Call: NoCall, Reason: f})
+		}
+		for _, c := range e.Calls {
+			for _, a := range c.Assertions {
+				if !a.Passed {
+					failures.Failures = append(failures.Failures, VerificationFailure{
+						Expectation: e.Name,
+						Call:        c.Index,
+						Reason:      a.Message,
+						Diff:        a.Diff,
+					})
+				}
+			}
+		}
+	}
+	return failures.orNil()
+}
+
+// JSON returns the report in JSON format.
+func (r *VerificationReport) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	return data, errors.Wrap(err, "unable to marshal verification report")
+}
+
+// String returns human readable report.
+func (r *VerificationReport) String() string {
+	return r.text(false)
+}
+
+// Colored returns human readable report with colored diffs for terminal output.
+func (r *VerificationReport) Colored() string {
+	return r.text(true)
+}
+
+func (r *VerificationReport) text(color bool) string {
+	var b strings.Builder
+	for _, e := range r.Expectations {
+		e.write(&b, color)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (e ExpectationReport) write(b *strings.Builder, color bool) {
+	fmt.Fprintf(b, "%s expectation [%s] %s %s\n", status(e.Passed), e.Name, e.Method, e.Path)
+	for _, f := range e.Failures {
+		fmt.Fprintf(b, "\t%s\n", f)
+	}
+	for _, c := range e.Calls {
+		if c.Missing {
+			fmt.Fprintf(b, "\tFAIL call [%d] is missing\n", c.Index)
+			continue
+		}
+		fmt.Fprintf(b, "\t%s call [%d] %s %s\n", status(c.Passed), c.Index, c.Method, c.Path)
+		for _, a := range c.Assertions {
+			if a.Passed {
+				fmt.Fprintf(b, "\t\tPASS %s\n", a.Name)
+				continue
+			}
+			fmt.Fprintf(b, "\t\tFAIL %s: %s\n", a.Name, a.Message)
+			d := a.Diff
+			if color && d != "" {
+				d = diff.Colored(a.expected, a.actual)
+			}
+			for _, line := range strings.Split(d, "\n") {
+				if line != "" {
+					fmt.Fprintf(b, "\t\t\t%s\n", line)
+				}
+			}
+		}
+	}
+}
+
+func status(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// JUnit returns the report in JUnit XML format, each Expectation is a test case of the suite.
+func (r *VerificationReport) JUnit(suite string) ([]byte, error) {
+	s := junitTestSuite{Name: suite, Tests: len(r.Expectations)}
+	for _, e := range r.Expectations {
+		tc := junitTestCase{Name: e.Name, ClassName: suite}
+		if !e.Passed {
+			var b strings.Builder
+			e.write(&b, false)
+			s.Failures++
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("expectation %s %s %s failed verification", e.Name, e.Method, e.Path),
+				Text:    b.String(),
+			}
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{s}}, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal verification report")
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,120 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestReport(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	all := mock.On(http.MethodGet, "/pets").
+		Name("All pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody([]pet{})).
+		NumCalls(1)
+	byID := mock.On(http.MethodGet, "/pets/{id}").
+		Name("Pet by id").
+		Request(msc.WithPathParameter("id", "[0-9]+")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "JoJo", Age: 2})).
+		AssertionAtCall(0, msc.NewAssertion().WithPath("/pets/2").WithNoBody())
+	require.NoError(t, mock.Setup(context.Background(), all, byID))
+
+	getPets(t, server.URL+"/pets")
+	getPet(t, server.URL+"/pets/1")
+
+	report, err := mock.Report(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Passed())
+	require.Len(t, report.Expectations, 2)
+	assert.True(t, report.Expectations[0].Passed)
+
+	call := report.Expectations[1].Calls[0]
+	assert.Equal(t, "/pets/1", call.Path)
+	require.Len(t, call.Assertions, 2)
+	assert.Equal(t, "body", call.Assertions[0].Name)
+	assert.True(t, call.Assertions[0].Passed)
+	assert.Equal(t, "path", call.Assertions[1].Name)
+	assert.False(t, call.Assertions[1].Passed)
+	assert.Equal(t, "--- expected\n+++ actual\n-/pets/2\n+/pets/1", call.Assertions[1].Diff)
+
+	data, err := report.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"name": "Pet by id"`)
+
+	data, err = report.JUnit("pets")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<testsuite name="pets" tests="2" failures="1">`)
+	assert.Contains(t, string(data), `<failure message="expectation Pet by id GET /pets/{id} failed verification">`)
+}
+
+func TestReportBodyDiff(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	create := mock.On(http.MethodPost, "/pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated)).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().EqualJSON(map[string]interface{}{"id": 1, "name": "Rex"}))
+	update := mock.On(http.MethodPut, "/pets").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK)).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().WithNoBody())
+	require.NoError(t, mock.Setup(context.Background(), create, update))
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		rq, err := http.NewRequest(method, server.URL+"/pets", strings.NewReader(`{"id":1,"name":"Tom"}`))
+		require.NoError(t, err)
+		rq.Header.Set("Content-Type", "application/json")
+		rs, err := http.DefaultClient.Do(rq)
+		require.NoError(t, err)
+		require.NoError(t, rs.Body.Close())
+	}
+
+	report, err := mock.Report(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Expectations, 2)
+
+	for name, tc := range map[string]struct {
+		expectation msc.ExpectationReport
+		assertion   string
+		diff        string
+	}{
+		"json body": {
+			expectation: report.Expectations[0],
+			assertion:   "json body",
+			diff: "--- expected\n+++ actual\n" +
+				" {\n" +
+				"   \"id\": 1,\n" +
+				"-  \"name\": \"Rex\"\n" +
+				"+  \"name\": \"Tom\"\n" +
+				" }",
+		},
+		"no body": {
+			expectation: report.Expectations[1],
+			assertion:   "body",
+			diff: "--- expected\n+++ actual\n" +
+				"-\n" +
+				"+{\n" +
+				"+  \"id\": 1,\n" +
+				"+  \"name\": \"Tom\"\n" +
+				"+}",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Len(t, tc.expectation.Calls, 1)
+			require.Len(t, tc.expectation.Calls[0].Assertions, 1)
+			a := tc.expectation.Calls[0].Assertions[0]
+			assert.Equal(t, tc.assertion, a.Name)
+			assert.False(t, a.Passed)
+			assert.Equal(t, tc.diff, a.Diff)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package mock_server_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+// RecordedRequest is HTTP request received by mock server app.
+type RecordedRequest struct {
+	Method          string
+	Path            string
+	QueryParameters map[string][]string
+	Headers         http.Header
+	Cookies         map[string]string
+	Body            []byte
+}
+
+// RecordedResponse is HTTP response returned by mock server app.
+type RecordedResponse struct {
+	StatusCode   int
+	ReasonPhrase string
+	Headers      http.Header
+	Cookies      map[string]string
+	Body         []byte
+}
+
+// RecordedRequestAndResponse is HTTP request received by mock server app together with the response it returned.
+// Timestamp is zero if mock server app didn't send it.
+type RecordedRequestAndResponse struct {
+	Request   RecordedRequest
+	Response  RecordedResponse
+	Timestamp time.Time
+}
+
+// RecordedExpectation is request and response recorded by mock server app working as a proxy.
+type RecordedExpectation struct {
+	Request  RecordedRequest
+	Response RecordedResponse
+}
+
+// ActiveExpectation is an expectation which is still active on mock server app. Each Expectation created by
+// MockServer.On is set up as several expectations on mock server app, one per SequentialResponse and one for the
+// default action. Name is Expectation name or id, it's empty if the expectation wasn't set up by the MockServer.
+type ActiveExpectation struct {
+	ID             string
+	Name           string
+	Priority       int
+	Method         string
+	Path           string
+	RemainingTimes int
+	Unlimited      bool
+	// JSON is the expectation in mock server app representation.
+	JSON json.RawMessage
+}
+
+// RecordedRequests returns all requests received by mock server app in the order they were received.
+func (m *mockServer) RecordedRequests(ctx context.Context) ([]RecordedRequest, error) {
+	rs, err := m.client.Retrieve(ctx, m.scope.matcher())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get recorded requests")
+	}
+	out := make([]RecordedRequest, len(rs))
+	for i, r := range rs {
+		out[i] = m.toRecordedRequest(r)
+	}
+	return out, nil
+}
+
+// RecordedRequestsAndResponses returns all requests received by mock server app together with returned responses.
+func (m *mockServer) RecordedRequestsAndResponses(ctx context.Context) ([]RecordedRequestAndResponse, error) {
+	rs, err := m.client.RetrieveRequestResponses(ctx, m.scope.matcher())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get recorded requests and responses")
+	}
+	out := make([]RecordedRequestAndResponse, len(rs))
+	for i, r := range rs {
+		if r.HTTPRequest != nil {
+			out[i].Request = m.toRecordedRequest(*r.HTTPRequest)
+		}
+		if r.HTTPResponse != nil {
+			out[i].Response = toRecordedResponse(*r.HTTPResponse)
+		}
+		out[i].Timestamp = parseTimestamp(r.Timestamp)
+	}
+	return out, nil
+}
+
+// ActiveExpectations returns expectations which are still active on mock server app.
+func (m *mockServer) ActiveExpectations(ctx context.Context) ([]ActiveExpectation, error) {
+	rs, err := m.client.RetrieveActiveExpectations(ctx, client.RetrieveRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get active expectations")
+	}
+
+	names := map[string]string{}
+	for _, e := range m.expectations {
+		for _, id := range e.clientIDs {
+			names[id] = e.String()
+		}
+	}
+	if m.scope != nil {
+		own := rs[:0:0]
+		for _, e := range rs {
+			if _, ok := names[e.ID]; ok {
+				own = append(own, e)
+			}
+		}
+		rs = own
+	}
+
+	out := make([]ActiveExpectation, len(rs))
+	for i, e := range rs {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to marshal active expectation %s", e.ID)
+		}
+		out[i] = ActiveExpectation{
+			ID:        e.ID,
+			Name:      names[e.ID],
+			Priority:  e.Priority,
+			Unlimited: e.Times == nil || e.Times.Unlimited,
+			JSON:      data,
+		}
+		if e.HTTPRequest != nil {
+			out[i].Method = e.HTTPRequest.Method
+			out[i].Path = m.scope.trimPath(e.HTTPRequest.Path)
+		}
+		if e.Times != nil && !e.Times.Unlimited {
+			out[i].RemainingTimes = e.Times.RemainingTimes
+		}
+	}
+	return out, nil
+}
+
+// RecordedExpectations returns requests and responses recorded by mock server app working as a proxy,
+// GenerateExpectationsCode turns them into Go code.
+func (m *mockServer) RecordedExpectations(ctx context.Context) ([]RecordedExpectation, error) {
+	rs, err := m.client.RetrieveRecordedExpectations(ctx, m.scope.matcher())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get recorded expectations")
+	}
+	out := make([]RecordedExpectation, len(rs))
+	for i, e := range rs {
+		if e.HTTPRequest != nil {
+			out[i].Request = m.toRecordedRequest(*e.HTTPRequest)
+		}
+		if e.HTTPResponse != nil {
+			out[i].Response = toRecordedResponse(*e.HTTPResponse)
+		}
+	}
+	return out, nil
+}
+
+// LogMessages returns log messages of mock server app, it's useful to find out why a request didn't match.
+func (m *mockServer) LogMessages(ctx context.Context) ([]string, error) {
+	messages, err := m.client.RetrieveLogMessages(ctx, client.RetrieveRequest{})
+	if err != nil || m.scope == nil {
+		return messages, errors.Wrap(err, "unable to get log messages")
+	}
+	// log messages are retrieved without request matcher, so the scope ones are found by the scope id
+	own := messages[:0:0]
+	for _, message := range messages {
+		if strings.Contains(message, m.scope.id) {
+			own = append(own, message)
+		}
+	}
+	return own, nil
+}
+
+// toRecordedRequest converts recorded request removing the scope path prefix.
+func (m *mockServer) toRecordedRequest(r client.HTTPRequest) RecordedRequest {
+	rq := toRecordedRequest(r)
+	rq.Path = m.scope.trimPath(rq.Path)
+	return rq
+}
+
+func toRecordedRequest(r client.HTTPRequest) RecordedRequest {
+	body, _ := client.BodyBytes(r.Body)
+	return RecordedRequest{
+		Method:          r.Method,
+		Path:            r.Path,
+		QueryParameters: fromClientMultiValues(r.QueryStringParameters),
+		Headers:         toHTTPHeader(r.Headers),
+		Cookies:         r.Cookies,
+		Body:            body,
+	}
+}
+
+func toRecordedResponse(r client.HTTPResponse) RecordedResponse {
+	body, _ := client.BodyBytes(r.Body)
+	return RecordedResponse{
+		StatusCode:   r.StatusCode,
+		ReasonPhrase: r.ReasonPhrase,
+		Headers:      toHTTPHeader(r.Headers),
+		Cookies:      r.Cookies,
+		Body:         body,
+	}
+}
+
+func toHTTPHeader(headers map[string]interface{}) http.Header {
+	out := http.Header{}
+	for k, v := range headers {
+		for _, value := range client.Values(v) {
+			out.Add(k, value)
+		}
+	}
+	return out
+}
+
+// parseTimestamp parses timestamp in mock server app format, zero time is returned if it can't be parsed.
+func parseTimestamp(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05.000", s, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
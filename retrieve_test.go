@@ -0,0 +1,62 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestRecordedRequests(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	read := mock.On(http.MethodGet, "/pets/{pet_id}").
+		Name("Read pet").
+		Request(msc.WithPathParameter("pet_id", "[0-9]+")).
+		SequentialResponse(
+			msc.WithStatusCode(http.StatusOK),
+			msc.WithResponseBody(pet{Name: "JoJo", Age: 2}),
+		).
+		DefaultResponse(msc.WithStatusCode(http.StatusNotFound))
+	require.NoError(t, mock.Setup(context.Background(), read))
+
+	active, err := mock.ActiveExpectations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	for _, e := range active {
+		assert.Equal(t, "Read pet", e.Name)
+		assert.Equal(t, "/pets/{pet_id}", e.Path)
+	}
+
+	assert.Equal(t, pet{Name: "JoJo", Age: 2}, getPet(t, server.URL+"/pets/1"))
+	assert.Equal(t, http.StatusNotFound, postPet(t, server.URL+"/pets", pet{Name: "PoPo", Age: 3}))
+
+	requests, err := mock.RecordedRequests(context.Background())
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/pets/1", requests[0].Path)
+	assert.Equal(t, http.MethodPost, requests[1].Method)
+	assert.JSONEq(t, `{"name":"PoPo","age":3}`, string(requests[1].Body))
+
+	exchanges, err := mock.RecordedRequestsAndResponses(context.Background())
+	require.NoError(t, err)
+	require.Len(t, exchanges, 2)
+	assert.Equal(t, http.StatusOK, exchanges[0].Response.StatusCode)
+	assert.JSONEq(t, `{"name":"JoJo","age":2}`, string(exchanges[0].Response.Body))
+	assert.Equal(t, http.StatusNotFound, exchanges[1].Response.StatusCode)
+	assert.False(t, exchanges[0].Timestamp.IsZero())
+
+	active, err = mock.ActiveExpectations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.True(t, active[0].Unlimited)
+
+	messages, err := mock.LogMessages(context.Background())
+	require.NoError(t, err)
+	assert.NotEmpty(t, messages)
+}
@@ -0,0 +1,140 @@
+package mock_server_client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+// ScopedMockServer is MockServer which sees only its own expectations and traffic, so parallel tests can share
+// a single mock server app. Requests of the system under test have to be sent to the scope by PathPrefix or Header.
+type ScopedMockServer interface {
+	MockServer
+
+	// PathPrefix returns prefix which has to be added to the base URL used by the system under test, for example
+	// 		client := pet.NewPetClient(server.URL + scoped.PathPrefix())
+	// It's empty when the scope is identified by header.
+	PathPrefix() string
+	// Header returns header which has to be sent by the system under test, it's empty when the scope is
+	// identified by path prefix.
+	Header() http.Header
+}
+
+// ScopeOption configures how the scope is identified in requests.
+type ScopeOption func(*scope)
+
+// WithScopeHeader identifies the scope by the header instead of path prefix, it's useful when the base URL of the
+// system under test can not contain a path.
+func WithScopeHeader(name string) ScopeOption {
+	return func(s *scope) {
+		s.header = http.CanonicalHeaderKey(name)
+	}
+}
+
+type scope struct {
+	id     string
+	header string
+}
+
+func (s *scope) pathPrefix() string {
+	if s == nil || s.header != "" {
+		return ""
+	}
+	return "/" + s.id
+}
+
+func (s *scope) apply(e *Expectation) {
+	if s == nil {
+		return
+	}
+	e.request.scoped = true
+	if s.header == "" {
+		e.request.scopePrefix = s.pathPrefix()
+		return
+	}
+	if e.request.headers == nil {
+		e.request.headers = map[string][]string{}
+	}
+	e.request.headers[s.header] = append(e.request.headers[s.header], s.id)
+}
+
+// matcher returns request matcher of the scope traffic.
+func (s *scope) matcher() client.RetrieveRequest {
+	if s == nil {
+		return client.RetrieveRequest{}
+	}
+	if s.header == "" {
+		return client.RetrieveRequest{Path: s.pathPrefix() + "/.*"}
+	}
+	return client.RetrieveRequest{Headers: map[string]interface{}{s.header: []string{s.id}}}
+}
+
+// trimPath removes the scope prefix from the path of recorded request.
+func (s *scope) trimPath(path string) string {
+	if prefix := s.pathPrefix(); prefix != "" {
+		return strings.TrimPrefix(path, prefix)
+	}
+	return path
+}
+
+// Scope creates ScopedMockServer on the same mock server app for the test. Every Expectation created by the scope
+// matches only requests sent with the scope path prefix (or header, see WithScopeHeader), Verify... and retrieval
+// methods see only the scope traffic, Reset removes only the scope expectations. The scope expectations are removed
+// when the test and its subtests complete, so parallel tests don't trample each other:
+// 		func TestSomething(t *testing.T) {
+// 			t.Parallel()
+// 			scoped := mock.Scope(t)
+// 			client := pet.NewPetClient(server.URL + scoped.PathPrefix())
+// 			...
+// 			scoped.Setup(ctx, scoped.On(http.MethodGet, "/pets").DefaultResponse(...))
+// 			...
+// 			scoped.Verify(ctx, t)
+// 		}
+// Scope of ScopedMockServer creates a new independent scope on the same mock server app.
+func (m *mockServer) Scope(t testing.TB, opts ...ScopeOption) ScopedMockServer {
+	s := &scope{id: uuid.NewString()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	scoped := &mockServer{
+		client:       m.client,
+		expectations: map[string]*Expectation{},
+		scope:        s,
+	}
+	t.Cleanup(func() {
+		if err := scoped.Reset(context.Background()); err != nil {
+			t.Errorf("unable to clear scope %s: %v", s.id, err)
+		}
+	})
+	return scoped
+}
+
+// PathPrefix returns path prefix of the scope, it's empty for not scoped MockServer.
+func (m *mockServer) PathPrefix() string {
+	return m.scope.pathPrefix()
+}
+
+// Header returns header of the scope, it's empty for not scoped MockServer.
+func (m *mockServer) Header() http.Header {
+	h := http.Header{}
+	if m.scope != nil && m.scope.header != "" {
+		h.Set(m.scope.header, m.scope.id)
+	}
+	return h
+}
+
+// ownIDs returns ids of expectations created on mock server app by the scope.
+func (m *mockServer) ownIDs() map[string]struct{} {
+	ids := map[string]struct{}{}
+	for _, e := range m.expectations {
+		for _, id := range e.clientIDs {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
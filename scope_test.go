@@ -0,0 +1,156 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestScopedAssertions(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+	scoped := mock.Scope(t)
+
+	byID := scoped.On(http.MethodGet, "/pets/{petId}").
+		Request(msc.WithPathParameter("petId", "[0-9]+")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK)).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().
+			WithPath("/pets/1").
+			ConformsToOpenAPI("testdata/pets.yaml", "showPetById"))
+	require.NoError(t, scoped.Setup(context.Background(), byID))
+
+	assert.Equal(t, http.StatusOK, get(t, server.URL+scoped.PathPrefix()+"/pets/1"))
+
+	assert.NoError(t, scoped.Check(context.Background()))
+}
+
+func TestScopedAssertionFailure(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+	scoped := mock.Scope(t)
+
+	e := scoped.On(http.MethodGet, "/pets/{petId}").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK)).
+		NumCalls(2).
+		AssertionAtCall(0, msc.NewAssertion().
+			WithPath("/pets/2").
+			ConformsToOpenAPI("testdata/pets.yaml", "showPetById"))
+	require.NoError(t, scoped.Setup(context.Background(), e))
+
+	assert.Equal(t, http.StatusOK, get(t, server.URL+scoped.PathPrefix()+"/pets/abc"))
+
+	var verificationErr *msc.VerificationError
+	require.ErrorAs(t, scoped.Check(context.Background()), &verificationErr)
+	require.Len(t, verificationErr.Failures, 3)
+	assert.Equal(t, "expected num calls to /pets/{petId}: 2; actual: 1", verificationErr.Failures[0].Reason)
+	assert.Equal(t, "expected path /pets/2; actual path /pets/abc", verificationErr.Failures[1].Reason)
+	assert.Contains(t, verificationErr.Failures[2].Reason, "request does not conform to openapi operation")
+	assert.NotContains(t, verificationErr.Error(), scoped.PathPrefix())
+}
+
+func TestScopedNotLeak(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	for name, scoped := range map[string]msc.ScopedMockServer{
+		"path prefix": mock.Scope(t),
+		"header":      mock.Scope(t, msc.WithScopeHeader("X-Scope")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			notAdmin := scoped.On(http.MethodGet, "/users").
+				Request(msc.WithRequestHeader("X-User", "admin")).
+				DefaultResponse(msc.WithStatusCode(http.StatusTeapot)).
+				Not()
+			err := scoped.Setup(context.Background(), notAdmin)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "scoped expectation can not be negated by Not")
+
+			assert.Equal(t, http.StatusNotFound, get(t, server.URL+mock.Scope(t).PathPrefix()+"/anything"))
+			assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/x"))
+		})
+	}
+}
+
+func TestScopedNotPath(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	prefixed := mock.Scope(t)
+	err := prefixed.Setup(context.Background(), prefixed.On(http.MethodGet, "/pets/.*").Request(msc.NotPath()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "path of scoped expectation can not be negated by NotPath")
+
+	scoped := mock.Scope(t, msc.WithScopeHeader("X-Scope"))
+	other := mock.Scope(t, msc.WithScopeHeader("X-Scope"))
+	notPets := scoped.On(http.MethodGet, "/pets/.*").
+		Request(msc.NotPath()).
+		DefaultResponse(msc.WithStatusCode(http.StatusAccepted)).
+		NumCalls(1).
+		AssertionAtCall(0, msc.NewAssertion().WithPath("/dogs/1"))
+	require.NoError(t, scoped.Setup(context.Background(), notPets))
+
+	send := func(path string, header http.Header) int {
+		rq, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		rq.Header = header
+		rs, err := http.DefaultClient.Do(rq)
+		require.NoError(t, err)
+		require.NoError(t, rs.Body.Close())
+		return rs.StatusCode
+	}
+	assert.Equal(t, http.StatusNotFound, send("/pets/1", scoped.Header()))
+	assert.Equal(t, http.StatusAccepted, send("/dogs/1", scoped.Header()))
+	// negated path doesn't match traffic of other scopes and not scoped traffic
+	assert.Equal(t, http.StatusNotFound, send("/dogs/1", other.Header()))
+	assert.Equal(t, http.StatusNotFound, send("/dogs/1", http.Header{}))
+
+	assert.NoError(t, scoped.Check(context.Background()))
+}
+
+func TestParallelScopes(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+	t.Cleanup(func() {
+		active, err := mock.ActiveExpectations(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, active, "scopes are not cleared")
+	})
+
+	for i := 1; i <= 5; i++ {
+		age := i
+		t.Run("pet "+strconv.Itoa(age), func(t *testing.T) {
+			t.Parallel()
+			scoped := mock.Scope(t)
+
+			e := scoped.On(http.MethodGet, "/pets").
+				DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody([]pet{{Name: "JoJo", Age: age}})).
+				NumCalls(2)
+			require.NoError(t, scoped.Setup(context.Background(), e))
+
+			for j := 0; j < 2; j++ {
+				assert.Equal(t, []pet{{Name: "JoJo", Age: age}}, getPets(t, server.URL+scoped.PathPrefix()+"/pets"))
+			}
+
+			require.NoError(t, scoped.Verify(context.Background(), t))
+			recorded, err := scoped.RecordedRequests(context.Background())
+			require.NoError(t, err)
+			require.Len(t, recorded, 2)
+			assert.Equal(t, "/pets", recorded[0].Path)
+		})
+	}
+}
+
+func get(t *testing.T, url string) int {
+	t.Helper()
+	rs, err := http.Get(url)
+	require.NoError(t, err)
+	require.NoError(t, rs.Body.Close())
+	return rs.StatusCode
+}
@@ -0,0 +1,91 @@
+package mock_server_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+)
+
+// Subscribe delivers each request matching the Expectation as soon as mock server app receives it, so the test
+// can react to calls of the system under test instead of polling:
+// 		ctx, cancel := context.WithCancel(ctx)
+// 		defer cancel()
+// 		for rq := range mock.Subscribe(ctx, e) {
+// 			...
+// 		}
+// Requests received before the subscription are delivered first. The log of mock server app is polled and
+// the requests received after the last delivered one are sent, the position in the log is tracked by timestamps
+// of the requests, so the requests received after the log is reset are delivered too. Mock server app can't filter
+// the log by time, so each poll retrieves all matching requests; failed polls are repeated.
+// The channel is closed when the context is done.
+func (m *mockServer) Subscribe(ctx context.Context, expectation *Expectation) <-chan RecordedRequest {
+	out := make(chan RecordedRequest)
+	matcher := client.RetrieveRequest(clientHttpRequest(expectation.request))
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(defaultPollInterval)
+		defer ticker.Stop()
+
+		cursor := logCursor{}
+		for {
+			rs, err := m.client.RetrieveRequestResponses(ctx, matcher)
+			if err == nil {
+				for _, r := range cursor.next(rs) {
+					select {
+					case out <- m.toRecordedRequest(*r.HTTPRequest):
+						cursor.advance(r)
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return out
+}
+
+// logCursor is the position in the log of mock server app: the timestamp of the last delivered request and
+// the number of delivered requests with that timestamp by the request, as timestamps have millisecond precision.
+type logCursor struct {
+	last      time.Time
+	delivered map[string]int
+}
+
+// next returns recorded requests received after the cursor, the log is ordered by time.
+func (c *logCursor) next(rs []client.HTTPRequestAndResponse) []client.HTTPRequestAndResponse {
+	var out []client.HTTPRequestAndResponse
+	seen := map[string]int{}
+	for _, r := range rs {
+		if r.HTTPRequest == nil {
+			continue
+		}
+		timestamp := parseTimestamp(r.Timestamp)
+		switch {
+		case timestamp.Before(c.last):
+			continue
+		case timestamp.Equal(c.last):
+			key := recordedExchangeKey(r)
+			seen[key]++
+			if seen[key] <= c.delivered[key] {
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func (c *logCursor) advance(r client.HTTPRequestAndResponse) {
+	timestamp := parseTimestamp(r.Timestamp)
+	if c.delivered == nil || !timestamp.Equal(c.last) {
+		c.last, c.delivered = timestamp, map[string]int{}
+	}
+	c.delivered[recordedExchangeKey(r)]++
+}
@@ -0,0 +1,48 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestSubscribe(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byID := mock.On(http.MethodGet, "/pets/{id}").
+		Request(msc.WithPathParameter("id", "[0-9]+")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "JoJo", Age: 2}))
+	require.NoError(t, mock.Setup(context.Background(), byID))
+
+	getPet(t, server.URL+"/pets/1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	requests := mock.Subscribe(ctx, byID)
+	// the channel is closed by the timeout, so missing request fails the test instead of blocking it
+	assert.Equal(t, "/pets/1", (<-requests).Path)
+
+	getPet(t, server.URL+"/pets/2")
+	assert.Equal(t, "/pets/2", (<-requests).Path)
+
+	// requests received after the log was reset are delivered, even if the log has as many requests as delivered
+	require.NoError(t, mock.Reset(context.Background()))
+	require.NoError(t, mock.Setup(context.Background(), mock.On(http.MethodGet, "/pets/{id}").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "PoPo", Age: 3}))))
+	getPet(t, server.URL+"/pets/3")
+	getPet(t, server.URL+"/pets/4")
+	assert.Equal(t, "/pets/3", (<-requests).Path)
+	assert.Equal(t, "/pets/4", (<-requests).Path)
+
+	cancel()
+	for range requests {
+		assert.Fail(t, "no request is expected")
+	}
+}
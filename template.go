@@ -0,0 +1,49 @@
+package mock_server_client
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/inmemory"
+	"github.com/YReshetko/mock-server-client/internal/mustache"
+)
+
+// RenderMustacheTemplate renders mustache response template for the HTTP request the same way as mock server app does,
+// so the template can be validated before MockServer.Setup. The path is the path of Expectation (for example
+// "/pets/{pet_id}"), it's used to resolve path parameters which are available as {{ request.pathParameters.pet_id.0 }}.
+// The request body is read by the function.
+func RenderMustacheTemplate(template, path string, r *http.Request) (string, error) {
+	t, err := mustache.Parse(template)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid mustache template")
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			return "", errors.Wrap(err, "unable to read request body")
+		}
+	}
+
+	cookies := map[string]string{}
+	for _, c := range r.Cookies() {
+		if _, ok := cookies[c.Name]; !ok {
+			cookies[c.Name] = c.Value
+		}
+	}
+
+	pathParams, _ := inmemory.PathParameters(path, r.URL.Path)
+	data := mustache.Request{
+		Method:                r.Method,
+		Path:                  r.URL.Path,
+		PathParameters:        pathParams,
+		QueryStringParameters: r.URL.Query(),
+		Headers:               r.Header,
+		Cookies:               cookies,
+		Body:                  string(body),
+	}
+	return t.Render(data.Data()), nil
+}
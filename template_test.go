@@ -0,0 +1,33 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestMustacheTemplate(t *testing.T) {
+	template := `{
+		"statusCode": 200,
+		"body": {"name": "Pet {{ request.pathParameters.pet_id.0 }}", "age": {{ request.pathParameters.pet_id.0 }}}
+	}`
+
+	rendered, err := msc.RenderMustacheTemplate(template, "/pets/{pet_id}", httptest.NewRequest(http.MethodGet, "/pets/3", nil))
+	require.NoError(t, err)
+	assert.Contains(t, rendered, `"name": "Pet 3", "age": 3`)
+
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	expectation := mock.On(http.MethodGet, "/pets/{pet_id}").
+		DefaultResponse(msc.WithMustacheTemplate(template))
+	require.NoError(t, mock.Setup(context.Background(), expectation))
+
+	assert.Equal(t, pet{Name: "Pet 5", Age: 5}, getPet(t, server.URL+"/pets/5"))
+}
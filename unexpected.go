@@ -0,0 +1,176 @@
+package mock_server_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/inmemory"
+)
+
+// VerifyNoUnexpectedRequests checks that every request received by mock server app matches some Expectation created
+// by On and set up on mock server app, so the test doesn't pass by accident when the system under test gets 404.
+// Test fails with the list of unexpected requests, each of them has a hint of the closest expectation, for example:
+// 		GET /pets/abc
+// 			closest expectation [Pet by id]: path didn't match
+// 			- path: "/pets/[0-9]+"
+// 			+ path: "/pets/abc"
+// The test fails and the error is returned if mock server app can not be reached.
+func (m *mockServer) VerifyNoUnexpectedRequests(ctx context.Context, t testing.TB) error {
+	t.Helper()
+	return report(t, m.CheckNoUnexpectedRequests(ctx))
+}
+
+// CheckNoUnexpectedRequests checks received requests the same way as VerifyNoUnexpectedRequests does,
+// but returns *VerificationError.
+func (m *mockServer) CheckNoUnexpectedRequests(ctx context.Context) error {
+	recorded, err := m.client.Retrieve(ctx, m.scope.matcher())
+	if err != nil {
+		return errors.Wrap(err, "unable to verify unexpected requests: unable to get recorded requests")
+	}
+	matchers, err := m.requestMatchers(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to verify unexpected requests")
+	}
+
+	var lines []string
+	for _, r := range recorded {
+		closest, matched := closestMismatch(matchers, r)
+		if matched {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("\t%s %s", r.Method, m.scope.trimPath(r.Path)))
+		if closest != nil {
+			lines = append(lines, closest.hint(r)...)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return &VerificationError{Failures: []VerificationFailure{{
+		Call:   NoCall,
+		Reason: "requests match no expectation:\n" + strings.Join(lines, "\n"),
+	}}}
+}
+
+// requestMatcher is request matcher of Expectation set up on mock server app.
+type requestMatcher struct {
+	name    string
+	request client.HTTPRequest
+	// matched contains recordedRequestKey of requests found by mock server app, it's used only if the request matcher
+	// can't be matched on the client side (see inmemory.Supported)
+	matched map[string]struct{}
+}
+
+// requestMatchers returns matchers of all []Expectation set up on mock server app in order they were created by On.
+func (m *mockServer) requestMatchers(ctx context.Context) ([]requestMatcher, error) {
+	var out []requestMatcher
+	for _, e := range m.sortedExpectations() {
+		if len(e.clientIDs) == 0 {
+			continue
+		}
+		rm := requestMatcher{name: e.String(), request: clientHttpRequest(e.request)}
+		if !inmemory.Supported(rm.request) {
+			rs, err := m.client.Retrieve(ctx, client.RetrieveRequest(rm.request))
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to get recorded requests of expectation %s", e)
+			}
+			rm.matched = map[string]struct{}{}
+			for _, r := range rs {
+				rm.matched[recordedRequestKey(r)] = struct{}{}
+			}
+		}
+		out = append(out, rm)
+	}
+	return out, nil
+}
+
+// match checks the recorded request, the fields which didn't match are returned for not matched request.
+func (rm requestMatcher) match(r client.HTTPRequest) (bool, []string) {
+	if rm.matched != nil {
+		_, ok := rm.matched[recordedRequestKey(r)]
+		return ok, nil
+	}
+	matched, reasons := inmemory.MatchRecorded(rm.request, r)
+	if matched {
+		return true, nil
+	}
+	var mismatched []string
+	for _, reason := range reasons {
+		if strings.HasSuffix(reason, didNotMatch) {
+			mismatched = append(mismatched, reason)
+		}
+	}
+	return false, mismatched
+}
+
+// recordedRequestKey identifies recorded request, identical requests are matched by the same expectations.
+func recordedRequestKey(r client.HTTPRequest) string {
+	data, _ := json.Marshal(r)
+	return string(data)
+}
+
+// mismatch describes why the request didn't match the request matcher.
+type mismatch struct {
+	matcher requestMatcher
+	reasons []string
+}
+
+const didNotMatch = "didn't match"
+
+// matcherFields maps fields reported by request matching to JSON fields of request matcher.
+var matcherFields = map[string]string{
+	"method":          "method",
+	"path":            "path",
+	"queryParameters": "queryStringParameters",
+	"headers":         "headers",
+	"cookies":         "cookies",
+	"body":            "body",
+}
+
+// closestMismatch returns the mismatch with the least number of not matched fields, or reports that the request
+// is matched by some of the matchers. There is no closest mismatch if no field can be blamed, for example
+// for the matchers negated by Not.
+func closestMismatch(matchers []requestMatcher, r client.HTTPRequest) (*mismatch, bool) {
+	var closest *mismatch
+	for _, rm := range matchers {
+		matched, reasons := rm.match(r)
+		if matched {
+			return nil, true
+		}
+		if len(reasons) > 0 && (closest == nil || len(reasons) < len(closest.reasons)) {
+			closest = &mismatch{matcher: rm, reasons: reasons}
+		}
+	}
+	return closest, false
+}
+
+// hint describes the mismatch as diff of expected and actual fields.
+func (mm mismatch) hint(actual client.HTTPRequest) []string {
+	lines := []string{fmt.Sprintf("\t\tclosest expectation [%s]: %s", mm.matcher.name, strings.Join(mm.reasons, ", "))}
+	expectedFields := jsonFields(mm.matcher.request)
+	actualFields := jsonFields(actual)
+	for _, reason := range mm.reasons {
+		field, ok := matcherFields[strings.TrimSpace(strings.TrimSuffix(reason, didNotMatch))]
+		if !ok {
+			continue
+		}
+		lines = append(lines,
+			fmt.Sprintf("\t\t- %s: %s", field, expectedFields[field]),
+			fmt.Sprintf("\t\t+ %s: %s", field, actualFields[field]),
+		)
+	}
+	return lines
+}
+
+func jsonFields(v interface{}) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	data, _ := json.Marshal(v)
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
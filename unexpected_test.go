@@ -0,0 +1,113 @@
+package mock_server_client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	msc "github.com/YReshetko/mock-server-client"
+)
+
+func TestNoUnexpectedRequests(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byID := mock.On(http.MethodGet, "/pets/[0-9]+").
+		Name("Pet by id").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK), msc.WithResponseBody(pet{Name: "JoJo", Age: 2}))
+	require.NoError(t, mock.Setup(context.Background(), byID))
+
+	assert.Equal(t, pet{Name: "JoJo", Age: 2}, getPet(t, server.URL+"/pets/1"))
+	assert.Equal(t, pet{Name: "JoJo", Age: 2}, getPet(t, server.URL+"/pets/2"))
+
+	require.NoError(t, mock.VerifyNoUnexpectedRequests(context.Background(), t))
+}
+
+func TestUnexpectedRequestHints(t *testing.T) {
+	mock, server := msc.NewInMemoryMockServer()
+	t.Cleanup(server.Close)
+
+	byID := mock.On(http.MethodGet, "/pets/[0-9]+").
+		Name("Pet by id").
+		DefaultResponse(msc.WithStatusCode(http.StatusOK))
+	create := mock.On(http.MethodPost, "/pets").
+		Name("Create pet").
+		Request(msc.WithQueryParameter("owner", "JoJo")).
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated))
+	require.NoError(t, mock.Setup(context.Background(), byID, create))
+
+	assert.Equal(t, http.StatusOK, get(t, server.URL+"/pets/1"))
+	assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/pets/abc"))
+	assert.Equal(t, http.StatusNotFound, postPet(t, server.URL+"/pets?owner=PoPo", pet{Name: "PoPo", Age: 3}))
+
+	err := mock.CheckNoUnexpectedRequests(context.Background())
+	var verificationErr *msc.VerificationError
+	require.ErrorAs(t, err, &verificationErr)
+	require.Len(t, verificationErr.Failures, 1)
+	assert.Equal(t, "requests match no expectation:\n"+
+		"\tGET /pets/abc\n"+
+		"\t\tclosest expectation [Pet by id]: path didn't match\n"+
+		"\t\t- path: \"/pets/[0-9]+\"\n"+
+		"\t\t+ path: \"/pets/abc\"\n"+
+		"\tPOST /pets\n"+
+		"\t\tclosest expectation [Create pet]: queryParameters didn't match\n"+
+		"\t\t- queryStringParameters: {\"owner\":[\"JoJo\"]}\n"+
+		"\t\t+ queryStringParameters: {\"owner\":[\"PoPo\"]}",
+		verificationErr.Failures[0].Reason)
+}
+
+func TestUnexpectedRequestsOfMockServerApp(t *testing.T) {
+	// recorded requests in the form mock server app returns them, log messages are not available
+	controlPlane := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		matcher, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.URL.Path != "/retrieve":
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Query().Get("type") == "LOGS":
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.Contains(string(matcher), "XPATH"):
+			_, _ = w.Write([]byte(`[{"method": "POST", "path": "/pets", "body": {"type": "STRING", "string": "<pet><name>JoJo</name></pet>"}}]`))
+		default:
+			_, _ = w.Write([]byte(`[
+				{"method": "GET", "path": "/pets", "queryStringParameters": {"tag": ["dog", "cat"]},
+				 "headers": {"Host": ["localhost:1080"]}, "cookies": {"session": "s1"}},
+				{"method": "POST", "path": "/pets", "body": {"type": "STRING", "string": "<pet><name>JoJo</name></pet>"}},
+				{"method": "POST", "path": "/pets", "body": {"type": "JSON", "json": {"name": "PoPo"}}}
+			]`))
+		}
+	}))
+	t.Cleanup(controlPlane.Close)
+	mock := msc.NewMockServer(msc.Config{BaseURL: controlPlane.URL})
+
+	list := mock.On(http.MethodGet, "/pets").
+		Name("List pets").
+		Request(msc.WithQueryParameter("tag", "cat"), msc.WithRequestCookie("session", "s1")).
+		DefaultResponse(msc.WithStatusCode(http.StatusOK))
+	create := mock.On(http.MethodPost, "/pets").
+		Name("Create pet").
+		Request(msc.WithXPathBody("/pet[name='JoJo']")).
+		DefaultResponse(msc.WithStatusCode(http.StatusCreated))
+	require.NoError(t, mock.Setup(context.Background(), list, create))
+
+	err := mock.CheckNoUnexpectedRequests(context.Background())
+	var verificationErr *msc.VerificationError
+	require.ErrorAs(t, err, &verificationErr)
+	require.Len(t, verificationErr.Failures, 1)
+	// XPath body is matched by mock server app, so XML body is expected and the hint is given by the other expectation
+	assert.Equal(t, "requests match no expectation:\n"+
+		"\tPOST /pets\n"+
+		"\t\tclosest expectation [List pets]: method didn't match, queryParameters didn't match, cookies didn't match\n"+
+		"\t\t- method: \"GET\"\n"+
+		"\t\t+ method: \"POST\"\n"+
+		"\t\t- queryStringParameters: {\"tag\":[\"cat\"]}\n"+
+		"\t\t+ queryStringParameters: null\n"+
+		"\t\t- cookies: {\"session\":\"s1\"}\n"+
+		"\t\t+ cookies: null",
+		verificationErr.Failures[0].Reason)
+}
@@ -3,8 +3,16 @@ package mock_server_client
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/YReshetko/mock-server-client/internal/client"
+	"github.com/YReshetko/mock-server-client/internal/openapi"
 )
 
 type bodyDecoder func(interface{}) error
@@ -17,12 +25,24 @@ type assertion struct {
 	headersRegexp map[string]*regexp.Regexp
 	noHeaders     map[string]struct{}
 
+	cookies   map[string]string
+	noCookies map[string]struct{}
+
 	queryParams      map[string]string
 	queryParamRegexp map[string]*regexp.Regexp
 	noQueryParams    map[string]struct{}
 
 	path                 string
 	requirePathAssertion bool
+
+	contract   *openAPIContract
+	jsonChecks []check
+	matchers   []check
+}
+
+type openAPIContract struct {
+	spec        string
+	operationID string
 }
 
 // NewAssertion creates new assertion to be checked on MockServer.Verify...
@@ -160,7 +180,11 @@ func (a *assertion) WithPlainTextBody(expectedBody *string) *assertion {
 func (a *assertion) WithNoBody() *assertion {
 	a.bodyDecoder = func(actualBody interface{}) error {
 		if actualBody != nil {
-			return fmt.Errorf("expected no body, but got %v", actualBody)
+			data, _ := client.BodyBytes(actualBody)
+			return &valueMismatch{
+				message: fmt.Sprintf("expected no body, but got %v", actualBody),
+				actual:  string(data),
+			}
 		}
 		return nil
 	}
@@ -217,6 +241,25 @@ func (a *assertion) WithNoHeader(key string) *assertion {
 	return a
 }
 
+// AddCookie adds a single cookie which sent within HTTP request to mock server.
+// Verifies exact match of cookie value.
+func (a *assertion) AddCookie(name, value string) *assertion {
+	if a.cookies == nil {
+		a.cookies = map[string]string{}
+	}
+	a.cookies[name] = value
+	return a
+}
+
+// WithNoCookie verifies that the particular cookie was not sent to mock server app withing particular request.
+func (a *assertion) WithNoCookie(name string) *assertion {
+	if a.noCookies == nil {
+		a.noCookies = map[string]struct{}{}
+	}
+	a.noCookies[name] = struct{}{}
+	return a
+}
+
 // WithQueryParameters sets required query parameters which sent within HTTP request to mock server.
 // Sets exactly those query parameters that have to be validated.
 // Verifies exact match of query parameters.
@@ -273,10 +316,159 @@ func (a *assertion) WithPath(path string) *assertion {
 	return a
 }
 
+// ConformsToOpenAPI verifies that the request conforms to the operation of OpenAPI 3 document (JSON or YAML):
+// path, query, header and cookie parameters and JSON body are validated against the operation schemas.
+// The spec is URL, file path or the document itself, for example:
+// 		expectation := serverMock.On(http.MethodPost, "/pets").
+// 			AssertionAtCall(0, msc.NewAssertion().ConformsToOpenAPI("testdata/petstore.yaml", "createPet"))
+func (a *assertion) ConformsToOpenAPI(spec, operationID string) *assertion {
+	a.contract = &openAPIContract{spec: spec, operationID: operationID}
+	return a
+}
+
+// NoCall is VerificationFailure.Call of failures which are not related to a particular call.
+const NoCall = -1
+
+// VerificationError is returned by MockServer.Check... methods, it contains every failed assertion.
+type VerificationError struct {
+	Failures []VerificationFailure
+}
+
+// VerificationFailure is a failed assertion of the Expectation at the call index, Call is NoCall
+// if the failure is not related to a particular call, for example for wrong number of calls.
+type VerificationFailure struct {
+	Expectation string
+	Call        int
+	Reason      string
+	// Diff is unified diff of expected and actual values if the assertion compares them.
+	Diff string
+}
+
+func (e *VerificationError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		failures[i] = f.String()
+	}
+	return strings.Join(failures, "\n")
+}
+
+func (e *VerificationError) add(expectation string, call int, err error) {
+	if err != nil {
+		e.Failures = append(e.Failures, VerificationFailure{Expectation: expectation, Call: call, Reason: err.Error()})
+	}
+}
+
+func (e *VerificationError) orNil() error {
+	if len(e.Failures) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (f VerificationFailure) String() string {
+	s := "FAIL assertion:\n"
+	if f.Expectation != "" {
+		s += fmt.Sprintf("Expectation name [%s]\n", f.Expectation)
+	}
+	if f.Call != NoCall {
+		s += fmt.Sprintf("Assertion at call [%d]\n", f.Call)
+	}
+	s += "Reason: " + f.Reason
+	if f.Diff != "" {
+		s += "\nDiff:\n" + f.Diff
+	}
+	return s
+}
+
+// valueMismatch is an assertion error with expected and actual values, so the failure can show their diff.
+type valueMismatch struct {
+	message  string
+	expected string
+	actual   string
+}
+
+func (e *valueMismatch) Error() string {
+	return e.message
+}
+
+type check struct {
+	name string
+	run  func(*verification) error
+}
+
+// checks returns the assertion checks in stable order, so the same failures are reported the same way.
+func (a *assertion) checks() []check {
+	var out []check
+	add := func(name string, run func(*verification) error) {
+		out = append(out, check{name: name, run: run})
+	}
+
+	if a.requireBodyAssertion {
+		add("body", func(v *verification) error { return v.assertBody(a.bodyDecoder) })
+	}
+	out = append(out, a.jsonChecks...)
+	if a.requirePathAssertion {
+		add("path", func(v *verification) error { return v.assertPath(a.path) })
+	}
+
+	for _, k := range sortedKeys(a.queryParamRegexp) {
+		k, r := k, a.queryParamRegexp[k]
+		add("query parameter "+k, func(v *verification) error { return v.assertQueryParameterRegexp(k, r) })
+	}
+	for _, k := range sortedKeys(a.queryParams) {
+		k, value := k, a.queryParams[k]
+		add("query parameter "+k, func(v *verification) error { return v.assertQueryParameter(k, value) })
+	}
+	for _, k := range sortedKeys(a.noQueryParams) {
+		k := k
+		add("no query parameter "+k, func(v *verification) error { return v.assertNoQueryParameter(k) })
+	}
+
+	for _, k := range sortedKeys(a.headers) {
+		k, value := k, a.headers[k]
+		add("header "+k, func(v *verification) error { return v.assertHeader(k, value) })
+	}
+	for _, k := range sortedKeys(a.headersRegexp) {
+		k, r := k, a.headersRegexp[k]
+		add("header "+k, func(v *verification) error { return v.assertHeaderRegexp(k, r) })
+	}
+	for _, k := range sortedKeys(a.noHeaders) {
+		k := k
+		add("no header "+k, func(v *verification) error { return v.assertNoHeader(k) })
+	}
+
+	for _, k := range sortedKeys(a.cookies) {
+		k, value := k, a.cookies[k]
+		add("cookie "+k, func(v *verification) error { return v.assertCookie(k, value) })
+	}
+	for _, k := range sortedKeys(a.noCookies) {
+		k := k
+		add("no cookie "+k, func(v *verification) error { return v.assertNoCookie(k) })
+	}
+
+	if a.contract != nil {
+		add("openapi "+a.contract.operationID, func(v *verification) error { return v.assertOpenAPI(a.contract) })
+	}
+	return append(out, a.matchers...)
+}
+
+// sortedKeys returns sorted keys of the map with string keys, so the map is processed in stable order.
+func sortedKeys(m interface{}) []string {
+	values := reflect.ValueOf(m).MapKeys()
+	keys := make([]string, len(values))
+	for i, k := range values {
+		keys[i] = k.String()
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type verification struct {
+	request     RecordedRequest
 	path        string
 	queryParams map[string][]string
 	headers     map[string]interface{}
+	cookies     map[string]string
 	body        interface{}
 }
 
@@ -296,12 +488,20 @@ func (v *verification) assertHeader(key string, value string) error {
 				return nil
 			}
 		}
-		return fmt.Errorf("for header %s expected value %s; actual values %v", key, value, av)
+		return &valueMismatch{
+			message:  fmt.Sprintf("for header %s expected value %s; actual values %v", key, value, av),
+			expected: value,
+			actual:   strings.Join(client.Values(av), "\n"),
+		}
 	case interface{}:
 		if av == value {
 			return nil
 		}
-		return fmt.Errorf("for header %s expected value %s; actual value %s", key, value, av)
+		return &valueMismatch{
+			message:  fmt.Sprintf("for header %s expected value %s; actual value %s", key, value, av),
+			expected: value,
+			actual:   fmt.Sprint(av),
+		}
 	default:
 		return fmt.Errorf("expected header %s is not a string %+v", key, av)
 	}
@@ -355,6 +555,29 @@ func (v *verification) assertNoHeader(key string) error {
 	return nil
 }
 
+func (v *verification) assertCookie(name string, value string) error {
+	actualValue, ok := v.cookies[name]
+	if !ok {
+		return errors.Errorf("no expected cookie: %s", name)
+	}
+	if actualValue != value {
+		return &valueMismatch{
+			message:  fmt.Sprintf("for cookie %s expected value %s; actual value %s", name, value, actualValue),
+			expected: value,
+			actual:   actualValue,
+		}
+	}
+	return nil
+}
+
+func (v *verification) assertNoCookie(name string) error {
+	val, ok := v.cookies[name]
+	if ok {
+		return errors.Errorf("unexpected cookie found %s for name '%s'", val, name)
+	}
+	return nil
+}
+
 func (v *verification) assertQueryParameter(key string, value string) error {
 	actualValue, ok := v.queryParams[key]
 	if !ok {
@@ -366,7 +589,11 @@ func (v *verification) assertQueryParameter(key string, value string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("for query parameter %s expected value %s; actual values %v", key, value, actualValue)
+	return &valueMismatch{
+		message:  fmt.Sprintf("for query parameter %s expected value %s; actual values %v", key, value, actualValue),
+		expected: value,
+		actual:   strings.Join(actualValue, "\n"),
+	}
 }
 
 func (v *verification) assertQueryParameterRegexp(key string, r *regexp.Regexp) error {
@@ -395,5 +622,57 @@ func (v *verification) assertPath(path string) error {
 	if v.path == path {
 		return nil
 	}
-	return fmt.Errorf("expected path %s; actual path %s", path, v.path)
+	return &valueMismatch{
+		message:  fmt.Sprintf("expected path %s; actual path %s", path, v.path),
+		expected: path,
+		actual:   v.path,
+	}
+}
+
+// openAPIDocuments caches loaded documents by spec as the same document is usually used by many assertions.
+var openAPIDocuments = struct {
+	sync.Mutex
+	docs map[string]*openapi.Document
+}{docs: map[string]*openapi.Document{}}
+
+func loadOpenAPI(spec string) (*openapi.Document, error) {
+	openAPIDocuments.Lock()
+	defer openAPIDocuments.Unlock()
+	if doc, ok := openAPIDocuments.docs[spec]; ok {
+		return doc, nil
+	}
+	doc, err := openapi.Load(spec)
+	if err != nil {
+		return nil, err
+	}
+	openAPIDocuments.docs[spec] = doc
+	return doc, nil
+}
+
+func (v *verification) assertOpenAPI(c *openAPIContract) error {
+	doc, err := loadOpenAPI(c.spec)
+	if err != nil {
+		return errors.Wrap(err, "unable to load openapi document")
+	}
+	o, ok := doc.Operation(c.operationID)
+	if !ok {
+		return errors.Errorf("operation %s is not found in openapi document", c.operationID)
+	}
+
+	body, _ := client.BodyBytes(v.body)
+	errs := doc.ValidateRequest(o, openapi.Request{
+		Path:    v.path,
+		Query:   v.queryParams,
+		Headers: toHTTPHeader(v.headers),
+		Cookies: v.cookies,
+		Body:    body,
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+	violations := make([]string, len(errs))
+	for i, err := range errs {
+		violations[i] = "\t- " + err.Error()
+	}
+	return errors.Errorf("request does not conform to openapi operation %s:\n%s", o, strings.Join(violations, "\n"))
 }